@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+const (
+	answersEnvPrefix = "KETTLE_"
+)
+
+var (
+	// Answers given with --answer key=value
+	flagAnswers = map[string]string{}
+
+	// Answers read from an answers file (YAML or JSON)
+	fileAnswers = map[string]string{}
+)
+
+// LoadAnswers reads the answers that prompts consult before asking the user;
+// answers given as key=value take precedence over those in the answers file
+func LoadAnswers(answersFile string, keyValues []string) error {
+	if answersFile != "" {
+		data, err := os.ReadFile(answersFile)
+		if err != nil {
+			return NewConfigError(err)
+		}
+
+		// JSON is valid YAML, so both formats can be read here
+		var values map[interface{}]interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return NewConfigError(fmt.Errorf("could not parse %s: %s", answersFile, err))
+		}
+		flattenAnswers("", values, fileAnswers)
+	}
+
+	for _, keyValue := range keyValues {
+		parts := strings.SplitN(keyValue, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return NewUserInputError("invalid answer %q (expected key=value)", keyValue)
+		}
+		flagAnswers[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+// flattenAnswers converts nested values into dotted keys,
+// e.g. {aws: {region: eu-west-1}} becomes aws.region=eu-west-1
+func flattenAnswers(prefix string, values map[interface{}]interface{}, answers map[string]string) {
+	for k, v := range values {
+		key := fmt.Sprintf("%v", k)
+		if prefix != "" {
+			key = fmt.Sprintf("%s.%s", prefix, key)
+		}
+		if nested, ok := v.(map[interface{}]interface{}); ok {
+			flattenAnswers(key, nested, answers)
+			continue
+		}
+		answers[key] = fmt.Sprintf("%v", v)
+	}
+}
+
+// SetAnswer sets the answer for a prompt, e.g. from a command's flag
+func SetAnswer(key, value string) {
+	flagAnswers[key] = value
+}
+
+// HasAnswer returns true if a prompt has been answered with --answer,
+// its environment variable or the answers file
+func HasAnswer(key string) bool {
+	_, inFlags := flagAnswers[key]
+	_, inEnv := os.LookupEnv(AnswerEnvVar(key))
+	_, inFile := fileAnswers[key]
+	return inFlags || inEnv || inFile
+}
+
+// AnswerEnvVar returns the environment variable that can hold the
+// answer for a prompt, e.g. aws.region is KETTLE_AWS_REGION
+func AnswerEnvVar(key string) string {
+	replacer := strings.NewReplacer(".", "_", "-", "_")
+	return answersEnvPrefix + strings.ToUpper(replacer.Replace(key))
+}
+
+// getAnswer looks up the answer for a prompt key in the flags, then the
+// environment, and then the answers file. It returns an error if there
+// is no answer and kettle is running in non-interactive mode
+func getAnswer(key string) (string, bool, error) {
+	if answer, ok := flagAnswers[key]; ok {
+		return answer, true, nil
+	}
+	if answer, ok := os.LookupEnv(AnswerEnvVar(key)); ok {
+		return answer, true, nil
+	}
+	if answer, ok := fileAnswers[key]; ok {
+		return answer, true, nil
+	}
+	if settings.NonInteractive {
+		return "", false, NewUserInputError("no answer for %q in non-interactive mode (use --answer %s=<value>, %s or an --answers-file)",
+			key, key, AnswerEnvVar(key))
+	}
+	return "", false, nil
+}
+
+func unknownAnswer(key, answer string, values map[string]string) error {
+	options := []string{}
+	for label, value := range values {
+		options = append(options, fmt.Sprintf("%s (%s)", value, label))
+	}
+	sort.Strings(options)
+	return NewUserInputError("invalid answer for %q: %s (options are: %s)", key, answer, strings.Join(options, ", "))
+}
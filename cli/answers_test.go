@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHasAnswer(t *testing.T) {
+	defer func() {
+		flagAnswers = map[string]string{}
+		fileAnswers = map[string]string{}
+		os.Unsetenv("KETTLE_AWS_REGION")
+	}()
+
+	if HasAnswer("aws.delete_role") {
+		t.Errorf("HasAnswer(aws.delete_role) = true without an answer")
+	}
+	if err := LoadAnswers("", []string{"aws.delete_role=yes"}); err != nil {
+		t.Fatal(err)
+	}
+	fileAnswers["gcloud.project"] = "kettle"
+	os.Setenv("KETTLE_AWS_REGION", "eu-west-1")
+
+	for _, key := range []string{"aws.delete_role", "gcloud.project", "aws.region"} {
+		if !HasAnswer(key) {
+			t.Errorf("HasAnswer(%s) = false, want true", key)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -13,6 +12,10 @@ import (
 
 func getSpinner(statusMessage string) *spinner.Spinner {
 	s := spinner.New(spinner.CharSets[39], 100*time.Millisecond)
+	if JSONOutput() {
+		// The spinner's default writer is the original stdout
+		s.Writer = os.Stderr
+	}
 	s.Suffix = fmt.Sprintf("  %s...", statusMessage)
 	s.Start()
 	return s
@@ -24,18 +27,70 @@ func Execute(command string, args []string, statusMessage string) error {
 }
 
 func ExecuteWithResult(command string, args []string, statusMessage string) ([]byte, error) {
-	osCmd := exec.Command(command, args...)
+	return run(&Command{
+		Name:          command,
+		Args:          args,
+		StatusMessage: statusMessage,
+	})
+}
+
+// ExecuteSecret runs a command whose output is a secret (e.g. a password), which is not recorded
+func ExecuteSecret(command string, args []string, statusMessage string) ([]byte, error) {
+	return run(&Command{
+		Name:          command,
+		Args:          args,
+		StatusMessage: statusMessage,
+		Secret:        true,
+	})
+}
+
+// ExecuteWithInput runs a command that reads from stdin (e.g. docker login --password-stdin)
+func ExecuteWithInput(command string, args []string, stdin []byte, statusMessage string) error {
+	_, err := run(&Command{
+		Name:          command,
+		Args:          args,
+		StatusMessage: statusMessage,
+		Stdin:         stdin,
+	})
+	return err
+}
+
+func run(cmd *Command) ([]byte, error) {
 	if settings.DebugMode {
-		fmt.Println("\n", command, strings.Join(args, " "))
-		osCmd.Stderr = os.Stderr
+		fmt.Println("\n", cmd.Name, strings.Join(cmd.Args, " "))
 	} else {
-		s := getSpinner(statusMessage)
+		s := getSpinner(cmd.StatusMessage)
 		defer s.Stop()
 	}
 
-	output, err := osCmd.Output()
+	output, err := runner.Run(cmd)
 	if err != nil {
 		return nil, err
 	}
 	return output, nil
 }
+
+// Stream runs a command and writes its output to stdout as it is produced;
+// runners that cannot stream (e.g. when replaying a session) write the
+// output once the command has exited
+func Stream(command string, args []string, statusMessage string) error {
+	if settings.DebugMode {
+		fmt.Println("\n", command, strings.Join(args, " "))
+	}
+
+	cmd := &Command{
+		Name:          command,
+		Args:          args,
+		StatusMessage: statusMessage,
+	}
+	if streamRunner, ok := runner.(StreamRunner); ok {
+		return streamRunner.Stream(cmd, os.Stdout)
+	}
+
+	output, err := runner.Run(cmd)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(output)
+	return err
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes that kettle returns, based on the type of error
+const (
+	ExitCodeOK            = 0
+	ExitCodeUnknown       = 1 // Any error that does not have a type below
+	ExitCodeUserInput     = 2 // Invalid arguments, flags or prompt input
+	ExitCodeConfig        = 3 // Missing or invalid kettle.json or ~/.kettle.yaml
+	ExitCodeMissingTool   = 4 // A required tool (e.g. aws, gcloud) is not installed
+	ExitCodeCommandFailed = 5 // A command (e.g. aws, gcloud) exited with an error
+)
+
+// UserInputError is returned when the arguments, flags or
+// prompt input that the user has given are not valid
+type UserInputError struct {
+	Err error
+}
+
+func NewUserInputError(format string, a ...interface{}) error {
+	return &UserInputError{
+		Err: fmt.Errorf(format, a...),
+	}
+}
+
+func (e *UserInputError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UserInputError) Unwrap() error {
+	return e.Err
+}
+
+// MissingToolError is returned when a tool that kettle needs
+// (e.g. the aws or gcloud cli) is not installed
+type MissingToolError struct {
+	Tool string
+	Err  error
+}
+
+func (e *MissingToolError) Error() string {
+	return fmt.Sprintf("please install %s: %s", e.Tool, e.Err)
+}
+
+func (e *MissingToolError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is returned when a project's config (kettle.json)
+// or the global settings (~/.kettle.yaml) cannot be read or written
+type ConfigError struct {
+	Err error
+}
+
+func NewConfigError(err error) error {
+	return &ConfigError{
+		Err: err,
+	}
+}
+
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// CommandError is returned by a Runner when a command exits
+// with a non-zero code
+type CommandError struct {
+	Command  string
+	Args     []string
+	ExitCode int
+	Stderr   []byte
+}
+
+func (e *CommandError) Error() string {
+	// Only the sub-commands (not the flags and their values) are
+	// included, e.g. "aws lambda create-function"
+	name := []string{e.Command}
+	for _, arg := range e.Args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		name = append(name, arg)
+	}
+
+	message := fmt.Sprintf("%s failed with exit status %d", strings.Join(name, " "), e.ExitCode)
+	if stderr := strings.TrimSpace(string(e.Stderr)); stderr != "" {
+		message = fmt.Sprintf("%s:\n%s", message, stderr)
+	}
+	return message
+}
+
+// IsExitCode returns whether the error is from a command
+// that exited with the given code
+func IsExitCode(err error, exitCode int) bool {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.ExitCode == exitCode
+	}
+	return false
+}
+
+// ExitCode maps an error to the code that kettle exits with;
+// the outermost typed error takes precedence
+func ExitCode(err error) int {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch err.(type) {
+		case *UserInputError:
+			return ExitCodeUserInput
+		case *ConfigError:
+			return ExitCodeConfig
+		case *MissingToolError:
+			return ExitCodeMissingTool
+		case *CommandError:
+			return ExitCodeCommandFailed
+		}
+	}
+	return ExitCodeUnknown
+}
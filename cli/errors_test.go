@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	commandErr := &CommandError{Command: "aws", Args: []string{"lambda", "get-function"}, ExitCode: 254}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"untyped", errors.New("failed"), ExitCodeUnknown},
+		{"user input", NewUserInputError("invalid flag: %s", "--x"), ExitCodeUserInput},
+		{"config", NewConfigError(errors.New("no kettle.json")), ExitCodeConfig},
+		{"missing tool", &MissingToolError{Tool: "aws", Err: exec.ErrNotFound}, ExitCodeMissingTool},
+		{"command", commandErr, ExitCodeCommandFailed},
+		{"wrapped command", fmt.Errorf("deploying: %w", commandErr), ExitCodeCommandFailed},
+		{"outermost type", NewConfigError(commandErr), ExitCodeConfig},
+		{"user input in config", NewConfigError(&UserInputError{Err: errors.New("x")}), ExitCodeConfig},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExitCode(test.err); got != test.want {
+				t.Errorf("ExitCode() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsExitCode(t *testing.T) {
+	commandErr := &CommandError{Command: "aws", ExitCode: 254}
+	tests := []struct {
+		name     string
+		err      error
+		exitCode int
+		want     bool
+	}{
+		{"same code", commandErr, 254, true},
+		{"other code", commandErr, 255, false},
+		{"wrapped", fmt.Errorf("x: %w", commandErr), 254, true},
+		{"not a command error", errors.New("x"), 254, false},
+		{"nil", nil, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsExitCode(test.err, test.exitCode); got != test.want {
+				t.Errorf("IsExitCode() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCommandErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *CommandError
+		want string
+	}{
+		{
+			"without stderr",
+			&CommandError{Command: "aws", Args: []string{"lambda", "get-function", "--function-name", "x"}, ExitCode: 254},
+			"aws lambda get-function failed with exit status 254",
+		},
+		{
+			"with stderr",
+			&CommandError{Command: "gcloud", Args: []string{"run", "deploy"}, ExitCode: 1, Stderr: []byte("denied\n")},
+			"gcloud run deploy failed with exit status 1:\ndenied",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.err.Error(); got != test.want {
+				t.Errorf("Error() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -11,7 +11,22 @@ const (
 	PromptNoneOfTheseOption = "None of these (create a new one)"
 )
 
-func PromptForValue(label string, values map[string]string, addNoneOfThese bool) (string, error) {
+// Every prompt has a key, which is used to look up an answer (from flags,
+// environment variables or an answers file) before prompting the user
+
+func PromptForValue(key, label string, values map[string]string, addNoneOfThese bool) (string, error) {
+	answer, ok, err := getAnswer(key)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		if addNoneOfThese && (answer == "" || answer == PromptNoneOfTheseOption) {
+			return "", nil
+		}
+		_, value, err := matchAnswer(key, answer, values)
+		return value, err
+	}
+
 	valueLabels := []string{}
 	for valueLabel, _ := range values {
 		valueLabels = append(valueLabels, valueLabel)
@@ -28,7 +43,7 @@ func PromptForValue(label string, values map[string]string, addNoneOfThese bool)
 	}
 	_, result, err := prompt.Run()
 	if err != nil {
-		return "", err
+		return "", &UserInputError{Err: err}
 	}
 
 	if addNoneOfThese && result == PromptNoneOfTheseOption {
@@ -37,7 +52,21 @@ func PromptForValue(label string, values map[string]string, addNoneOfThese bool)
 	return values[result], nil
 }
 
-func PromptToConfirm(label string) bool {
+func PromptToConfirm(key, label string) (bool, error) {
+	answer, ok, err := getAnswer(key)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		switch strings.ToLower(answer) {
+		case "y", "yes", "true", "1":
+			return true, nil
+		case "n", "no", "false", "0":
+			return false, nil
+		}
+		return false, NewUserInputError("invalid answer for %q: %s (expected yes or no)", key, answer)
+	}
+
 	prompt := promptui.Prompt{
 		Label:     label,
 		IsConfirm: true,
@@ -45,16 +74,28 @@ func PromptToConfirm(label string) bool {
 
 	result, err := prompt.Run()
 	if err != nil {
-		return false
+		if err == promptui.ErrAbort {
+			// The user answered no
+			return false, nil
+		}
+		return false, &UserInputError{Err: err}
 	}
 
 	if strings.ToLower(result) == "y" {
-		return true
+		return true, nil
 	}
-	return false
+	return false, nil
 }
 
-func PromptForKeyValue(label string, values map[string]string) (string, string, error) {
+func PromptForKeyValue(key, label string, values map[string]string) (string, string, error) {
+	answer, ok, err := getAnswer(key)
+	if err != nil {
+		return "", "", err
+	}
+	if ok {
+		return matchAnswer(key, answer, values)
+	}
+
 	valueLabels := []string{}
 	for valueLabel, _ := range values {
 		valueLabels = append(valueLabels, valueLabel)
@@ -67,19 +108,44 @@ func PromptForKeyValue(label string, values map[string]string) (string, string,
 	}
 	_, result, err := prompt.Run()
 	if err != nil {
-		return "", "", err
+		return "", "", &UserInputError{Err: err}
 	}
 	return result, values[result], nil
 }
 
-func PromptForString(label string) (string, error) {
+func PromptForString(key, label string) (string, error) {
+	return PromptForStringWithDefault(key, label, "")
+}
+
+func PromptForStringWithDefault(key, label, defaultValue string) (string, error) {
+	answer, ok, err := getAnswer(key)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return answer, nil
+	}
+
 	prompt := promptui.Prompt{
-		Label: label,
+		Label:     label,
+		Default:   defaultValue,
+		AllowEdit: true,
 	}
 
 	result, err := prompt.Run()
 	if err != nil {
-		return "", err
+		return "", &UserInputError{Err: err}
 	}
 	return result, nil
 }
+
+// matchAnswer finds the label & value that an answer refers to;
+// answers can be either the label or the value
+func matchAnswer(key, answer string, values map[string]string) (string, string, error) {
+	for label, value := range values {
+		if answer == value || answer == label {
+			return label, value, nil
+		}
+	}
+	return "", "", unknownAnswer(key, answer, values)
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report describes what is deployed for a project, and
+// any mismatches with the project's kettle.json
+type Report struct {
+	Project     string            `json:"project"`
+	Environment string            `json:"environment,omitempty"`
+	Service     string            `json:"service"`
+	Deployed    bool              `json:"deployed"`
+	Details     map[string]string `json:"details"`
+	Mismatches  []string          `json:"mismatches"`
+
+	// The order in which details were added, for the table
+	keys []string
+}
+
+func NewReport(project, environment, service string) *Report {
+	return &Report{
+		Project:     project,
+		Environment: environment,
+		Service:     service,
+		Details:     map[string]string{},
+		Mismatches:  []string{},
+	}
+}
+
+// Set adds a detail to the report; key is snake_case (e.g. last_modified)
+func (r *Report) Set(key, value string) {
+	if _, ok := r.Details[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.Details[key] = value
+}
+
+// Mismatch adds a difference between the deployment and kettle.json
+func (r *Report) Mismatch(format string, args ...interface{}) {
+	r.Mismatches = append(r.Mismatches, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) WriteText(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Project\t%s\n", r.Project)
+	if r.Environment != "" {
+		fmt.Fprintf(tw, "Environment\t%s\n", r.Environment)
+	}
+	fmt.Fprintf(tw, "Service\t%s\n", r.Service)
+	fmt.Fprintf(tw, "Deployed\t%t\n", r.Deployed)
+	for _, key := range r.keys {
+		fmt.Fprintf(tw, "%s\t%s\n", getReportLabel(key), r.Details[key])
+	}
+	tw.Flush()
+
+	if len(r.Mismatches) > 0 {
+		fmt.Fprintln(w, "\n⚠️  Mismatches with kettle.json:")
+		for _, mismatch := range r.Mismatches {
+			fmt.Fprintf(w, "  - %s\n", mismatch)
+		}
+	}
+}
+
+var reportAcronyms = map[string]bool{
+	"api": true,
+	"arn": true,
+	"gcp": true,
+	"id":  true,
+	"mb":  true,
+	"url": true,
+}
+
+// getReportLabel converts a key to a label, e.g. rest_api_methods to Rest API methods
+func getReportLabel(key string) string {
+	words := strings.Split(key, "_")
+	for i, word := range words {
+		switch {
+		case reportAcronyms[word]:
+			words[i] = strings.ToUpper(word)
+		case i == 0:
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Result is the machine-readable result of a command, which is
+// written to stdout as a single JSON document with --output json
+type Result struct {
+	Command     string             `json:"command"`
+	Success     bool               `json:"success"`
+	Path        string             `json:"path,omitempty"`
+	Cloud       string             `json:"cloud,omitempty"`
+	Environment string             `json:"environment,omitempty"`
+	EndpointURL string             `json:"endpoint_url,omitempty"`
+	FunctionArn string             `json:"function_arn,omitempty"`
+	Version     string             `json:"version,omitempty"`
+	Deployment  *config.Deployment `json:"deployment,omitempty"`
+	Plan        *Plan              `json:"plan,omitempty"`
+	Report      *Report            `json:"report,omitempty"`
+	Response    *ResultResponse    `json:"response,omitempty"`
+	Warnings    []string           `json:"warnings"`
+	Error       *ResultError       `json:"error,omitempty"`
+
+	// Commands that stream their own output (e.g. logs) do not write a result
+	skip bool
+}
+
+// ResultResponse is the response of a function to kettle invoke
+type ResultResponse struct {
+	StatusCode int    `json:"status_code"`
+	Latency    string `json:"latency"`
+	Body       string `json:"body"`
+}
+
+type ResultError struct {
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+}
+
+var (
+	result = &Result{
+		Warnings: []string{},
+	}
+
+	// The original stdout, which human-readable output is moved away from
+	resultOutput = os.Stdout
+)
+
+// JSONOutput returns true with --output json
+func JSONOutput() bool {
+	return settings.OutputFormat == settings.OutputFormatJSON
+}
+
+// UseJSONOutput sends everything that is printed to stdout (e.g. progress
+// & prompts) to stderr instead, so that stdout only has the result
+func UseJSONOutput() {
+	os.Stdout = os.Stderr
+}
+
+// GetResult returns the result of the command that is running
+func GetResult() *Result {
+	return result
+}
+
+// SkipResult is used by commands that write their own output to stdout
+// (e.g. logs), which is moved back from stderr
+func SkipResult() {
+	result.skip = true
+	if JSONOutput() {
+		os.Stdout = resultOutput
+	}
+}
+
+// Warn prints a warning, and adds it to the result
+func Warn(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	result.Warnings = append(result.Warnings, message)
+	fmt.Printf("⚠️  %s\n", message)
+}
+
+// WriteResult writes the result of a command as JSON; err is the command's error, if it failed
+func WriteResult(command string, err error) error {
+	if result.skip && err == nil {
+		return nil
+	}
+	result.Command = command
+	result.Success = err == nil
+	if err != nil {
+		result.Error = &ResultError{
+			Message:  err.Error(),
+			ExitCode: ExitCode(err),
+		}
+	}
+
+	data, marshalErr := json.MarshalIndent(result, "", "  ")
+	if marshalErr != nil {
+		return marshalErr
+	}
+	_, writeErr := fmt.Fprintln(resultOutput, string(data))
+	return writeErr
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestResult writes a result as it would be at the end of a command, and reads it back
+func writeTestResult(t *testing.T, command string, set func(*Result), err error) map[string]interface{} {
+	t.Helper()
+	previousResult, previousOutput := result, resultOutput
+	defer func() {
+		result, resultOutput = previousResult, previousOutput
+	}()
+
+	f, createErr := os.Create(filepath.Join(t.TempDir(), "result.json"))
+	if createErr != nil {
+		t.Fatal(createErr)
+	}
+	defer f.Close()
+	result = &Result{Warnings: []string{}}
+	resultOutput = f
+
+	set(GetResult())
+	if writeErr := WriteResult(command, err); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	data, readErr := ioutil.ReadFile(f.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	document := map[string]interface{}{}
+	if jsonErr := json.Unmarshal(data, &document); jsonErr != nil {
+		t.Fatalf("result is not JSON: %s", data)
+	}
+	return document
+}
+
+func TestWriteResult(t *testing.T) {
+	document := writeTestResult(t, "invoke", func(r *Result) {
+		r.EndpointURL = "https://example.com/hello"
+		r.Response = &ResultResponse{StatusCode: 200, Latency: "12ms", Body: `{"ok": true}`}
+	}, nil)
+
+	if document["command"] != "invoke" || document["success"] != true {
+		t.Errorf("result = %v, want a successful invoke", document)
+	}
+	response, ok := document["response"].(map[string]interface{})
+	if !ok || response["status_code"] != float64(200) || response["body"] != `{"ok": true}` {
+		t.Errorf("response = %v", document["response"])
+	}
+	for _, key := range []string{"details", "error", "cloud"} {
+		if _, ok := document[key]; ok {
+			t.Errorf("result has %s: %v", key, document)
+		}
+	}
+}
+
+func TestWriteResultError(t *testing.T) {
+	document := writeTestResult(t, "init", func(r *Result) {
+		r.Cloud = "aws"
+	}, NewUserInputError("please specify a cloud"))
+
+	if document["success"] != false || document["cloud"] != "aws" {
+		t.Errorf("result = %v", document)
+	}
+	resultErr, ok := document["error"].(map[string]interface{})
+	if !ok || resultErr["message"] != "please specify a cloud" || resultErr["exit_code"] != float64(ExitCode(NewUserInputError(""))) {
+		t.Errorf("error = %v", document["error"])
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Command is a single invocation of an external tool (e.g. aws, gcloud)
+type Command struct {
+	Name          string   `json:"command"`
+	Args          []string `json:"args"`
+	StatusMessage string   `json:"status,omitempty"`
+
+	// Stdin is written to the command's input; it is not recorded in
+	// sessions or plans, since it can be a secret (e.g. a password)
+	Stdin []byte `json:"-"`
+
+	// Secret is set for commands whose output is a secret (e.g. a password
+	// or a token); their output is redacted when sessions are recorded
+	Secret bool `json:"-"`
+}
+
+// Runner executes commands on behalf of kettle. The default runner
+// shells out; it can be swapped for a fake (in tests) or for one that
+// records or replays sessions
+type Runner interface {
+	Run(cmd *Command) ([]byte, error)
+}
+
+var runner Runner = ExecRunner{}
+
+// SetRunner replaces the runner that is used by Execute and ExecuteWithResult
+func SetRunner(r Runner) {
+	runner = r
+}
+
+// StreamRunner is implemented by runners that can write a command's output
+// as it is produced (e.g. when tailing logs), instead of once it has exited
+type StreamRunner interface {
+	Stream(cmd *Command, stdout io.Writer) error
+}
+
+// GetRunner returns the runner that is currently in use
+func GetRunner() Runner {
+	return runner
+}
+
+// ExecRunner runs commands using os/exec
+type ExecRunner struct{}
+
+func (ExecRunner) Run(cmd *Command) ([]byte, error) {
+	var stderr bytes.Buffer
+	osCmd := exec.Command(cmd.Name, cmd.Args...)
+	osCmd.Stderr = &stderr
+	if settings.DebugMode {
+		osCmd.Stderr = io.MultiWriter(&stderr, os.Stderr)
+	}
+	if cmd.Stdin != nil {
+		osCmd.Stdin = bytes.NewReader(cmd.Stdin)
+	}
+
+	output, err := osCmd.Output()
+	if err != nil {
+		return nil, getCommandError(cmd, err, stderr.Bytes())
+	}
+	return output, nil
+}
+
+func (ExecRunner) Stream(cmd *Command, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	osCmd := exec.Command(cmd.Name, cmd.Args...)
+	osCmd.Stdout = stdout
+	osCmd.Stderr = &stderr
+	if settings.DebugMode {
+		osCmd.Stderr = io.MultiWriter(&stderr, os.Stderr)
+	}
+
+	if err := osCmd.Run(); err != nil {
+		return getCommandError(cmd, err, stderr.Bytes())
+	}
+	return nil
+}
+
+// getCommandError converts the error from os/exec into a typed error
+func getCommandError(cmd *Command, err error, stderr []byte) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &CommandError{
+			Command:  cmd.Name,
+			Args:     cmd.Args,
+			ExitCode: exitErr.ExitCode(),
+			Stderr:   stderr,
+		}
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return &MissingToolError{
+			Tool: cmd.Name,
+			Err:  err,
+		}
+	}
+	return err
+}
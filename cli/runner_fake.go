@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FakeResponse is a scripted result for a command; an argument of "*"
+// matches any single argument
+type FakeResponse struct {
+	Command  string   `json:"command"`
+	Args     []string `json:"args"`
+	Stdout   string   `json:"stdout,omitempty"`
+	Stderr   string   `json:"stderr,omitempty"`
+	ExitCode int      `json:"exit_code,omitempty"`
+
+	used bool
+}
+
+// FakeRunner returns scripted responses instead of running commands
+type FakeRunner struct {
+	Responses []*FakeResponse
+	Calls     []*Command
+}
+
+func NewFakeRunner(responses ...*FakeResponse) *FakeRunner {
+	return &FakeRunner{
+		Responses: responses,
+	}
+}
+
+// Expect adds a response for the given argv, which can then be
+// populated with the output to return
+func (f *FakeRunner) Expect(command string, args ...string) *FakeResponse {
+	response := &FakeResponse{
+		Command: command,
+		Args:    args,
+	}
+	f.Responses = append(f.Responses, response)
+	return response
+}
+
+func (f *FakeRunner) Run(cmd *Command) ([]byte, error) {
+	f.Calls = append(f.Calls, cmd)
+	for _, response := range f.Responses {
+		if response.used || !response.matches(cmd) {
+			continue
+		}
+
+		response.used = true
+		if response.ExitCode != 0 {
+			return nil, &CommandError{
+				Command:  cmd.Name,
+				Args:     cmd.Args,
+				ExitCode: response.ExitCode,
+				Stderr:   []byte(response.Stderr),
+			}
+		}
+		return []byte(response.Stdout), nil
+	}
+	return nil, fmt.Errorf("fake runner: unexpected command: %s %s", cmd.Name, strings.Join(cmd.Args, " "))
+}
+
+// Remaining returns the responses that have not been used
+func (f *FakeRunner) Remaining() []*FakeResponse {
+	remaining := []*FakeResponse{}
+	for _, response := range f.Responses {
+		if !response.used {
+			remaining = append(remaining, response)
+		}
+	}
+	return remaining
+}
+
+func (r *FakeResponse) matches(cmd *Command) bool {
+	if r.Command != cmd.Name || len(r.Args) != len(cmd.Args) {
+		return false
+	}
+	for i, arg := range r.Args {
+		if arg != "*" && arg != cmd.Args[i] {
+			return false
+		}
+	}
+	return true
+}
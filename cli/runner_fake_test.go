@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFakeRunner(t *testing.T) {
+	fake := NewFakeRunner()
+	fake.Expect("aws", "lambda", "get-function", "--function-name", "*").Stdout = `{"Configuration": {}}`
+	fake.Expect("aws", "lambda", "get-function", "--function-name", "*").ExitCode = 254
+
+	output, err := fake.Run(&Command{Name: "aws", Args: []string{"lambda", "get-function", "--function-name", "hello"}})
+	if err != nil || string(output) != `{"Configuration": {}}` {
+		t.Fatalf("first call = %q, %v", output, err)
+	}
+
+	// Each response is only used once
+	_, err = fake.Run(&Command{Name: "aws", Args: []string{"lambda", "get-function", "--function-name", "hello"}})
+	if !IsExitCode(err, 254) {
+		t.Fatalf("second call: err = %v, want exit code 254", err)
+	}
+
+	_, err = fake.Run(&Command{Name: "aws", Args: []string{"lambda", "get-function", "--function-name", "hello"}})
+	if err == nil || !strings.Contains(err.Error(), "unexpected command") {
+		t.Fatalf("third call: err = %v, want an unexpected command", err)
+	}
+	if len(fake.Calls) != 3 || len(fake.Remaining()) != 0 {
+		t.Errorf("calls = %d, remaining = %d", len(fake.Calls), len(fake.Remaining()))
+	}
+}
+
+func TestFakeResponseMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *FakeResponse
+		cmd      *Command
+		want     bool
+	}{
+		{"exact", &FakeResponse{Command: "git", Args: []string{"status"}}, &Command{Name: "git", Args: []string{"status"}}, true},
+		{"wildcard", &FakeResponse{Command: "git", Args: []string{"*"}}, &Command{Name: "git", Args: []string{"status"}}, true},
+		{"other command", &FakeResponse{Command: "aws", Args: []string{"status"}}, &Command{Name: "git", Args: []string{"status"}}, false},
+		{"other argument", &FakeResponse{Command: "git", Args: []string{"log"}}, &Command{Name: "git", Args: []string{"status"}}, false},
+		{"fewer arguments", &FakeResponse{Command: "git", Args: []string{}}, &Command{Name: "git", Args: []string{"status"}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.response.matches(test.cmd); got != test.want {
+				t.Errorf("matches() = %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Actions for each step in a plan
+const (
+	PlanActionRead   = "read"   // A read-only cloud command, which is run
+	PlanActionChange = "change" // A cloud command that changes resources, which is skipped
+	PlanActionLocal  = "local"  // A local command (e.g. building a binary), which is run
+)
+
+// Commands that are run against a cloud provider; all others are local
+var cloudCommands = map[string]bool{
+	"aws":    true,
+	"gcloud": true,
+}
+
+// Docker commands that build or publish images; as images are not built
+// in a plan, none of them are run (e.g. a tag would refer to a missing image)
+var dockerChanges = map[string]bool{
+	"build": true,
+	"tag":   true,
+	"login": true,
+	"push":  true,
+}
+
+// Output returned for commands that are skipped, so that subsequent
+// steps can use the (placeholder) IDs they would return
+var plannedOutputs = map[string]string{
+	"build":             "sha256:<image-id>",
+	"create-resource":   `{"id": "<new-resource-id>"}`,
+	"create-rest-api":   `{"id": "<new-rest-api-id>"}`,
+	"create-repository": `{"repository": {"repositoryUri": "<new-repository-uri>"}}`,
+	"create-role":       `{"Role": {"Arn": "<new-role-arn>"}}`,
+	"publish-version":   `{"Version": "<new-version>", "FunctionArn": "<new-version-arn>"}`,
+}
+
+// PlanStep is a single command in a plan
+type PlanStep struct {
+	Step        int      `json:"step"`
+	Action      string   `json:"action"`
+	Description string   `json:"description,omitempty"`
+	Command     string   `json:"command"`
+	Args        []string `json:"args"`
+}
+
+// Plan is the ordered list of commands that a deployment runs
+type Plan struct {
+	Steps []*PlanStep `json:"steps"`
+}
+
+// PlanRunner runs read-only cloud commands and local commands with
+// another runner, and records (but skips) cloud commands that change resources
+type PlanRunner struct {
+	Runner Runner
+	Plan   *Plan
+}
+
+func NewPlanRunner(inner Runner) *PlanRunner {
+	return &PlanRunner{
+		Runner: inner,
+		Plan: &Plan{
+			Steps: []*PlanStep{},
+		},
+	}
+}
+
+func (p *PlanRunner) Run(cmd *Command) ([]byte, error) {
+	action := getPlanAction(cmd)
+	p.Plan.Steps = append(p.Plan.Steps, &PlanStep{
+		Step:        len(p.Plan.Steps) + 1,
+		Action:      action,
+		Description: cmd.StatusMessage,
+		Command:     cmd.Name,
+		Args:        cmd.Args,
+	})
+	if action != PlanActionChange {
+		return p.Runner.Run(cmd)
+	}
+
+	if output, ok := plannedOutputs[getOperation(cmd)]; ok {
+		return []byte(output), nil
+	}
+	return []byte("{}"), nil
+}
+
+// Changes returns the number of steps that would change cloud resources
+func (p *Plan) Changes() int {
+	changes := 0
+	for _, step := range p.Steps {
+		if step.Action == PlanActionChange {
+			changes++
+		}
+	}
+	return changes
+}
+
+func (p *Plan) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "📋  Plan: %d step(s), %d change(s)\n", len(p.Steps), p.Changes())
+	for _, step := range p.Steps {
+		fmt.Fprintf(w, "%4d. %-7s %s\n", step.Step, step.Action, step.Description)
+		fmt.Fprintf(w, "      %-7s %s\n", "", FormatCommand(step.Command, step.Args))
+	}
+}
+
+// FormatCommand returns a command as it would be typed into a shell
+func FormatCommand(command string, args []string) string {
+	parts := []string{command}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'$&|;<>(){}*") {
+			arg = fmt.Sprintf("'%s'", strings.ReplaceAll(arg, "'", `'\''`))
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+func getPlanAction(cmd *Command) string {
+	if cmd.Name == "docker" {
+		if dockerChanges[getOperation(cmd)] {
+			return PlanActionChange
+		}
+		return PlanActionLocal
+	}
+	if !cloudCommands[cmd.Name] {
+		return PlanActionLocal
+	}
+
+	operation := getOperation(cmd)
+	for _, prefix := range []string{"get-", "list-", "head-", "describe"} {
+		if strings.HasPrefix(operation, prefix) {
+			return PlanActionRead
+		}
+	}
+	if operation == "list" || operation == "read" {
+		return PlanActionRead
+	}
+	return PlanActionChange
+}
+
+// getOperation returns the operation that a cloud command runs,
+// e.g. "get-function" in "aws lambda get-function --function-name x"
+// or "describe" in "gcloud run services describe x --format json"
+func getOperation(cmd *Command) string {
+	if cmd.Name == "docker" {
+		if len(cmd.Args) < 1 {
+			return ""
+		}
+		// docker buildx build builds images like docker build
+		if cmd.Args[0] == "buildx" && len(cmd.Args) > 1 {
+			return cmd.Args[1]
+		}
+		return cmd.Args[0]
+	}
+	if cmd.Name == "aws" {
+		if len(cmd.Args) < 2 {
+			return ""
+		}
+		return cmd.Args[1]
+	}
+
+	operation := ""
+	for i, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		// gcloud commands take positional names after the operation
+		if i > 0 && isGcloudOperation(operation) {
+			break
+		}
+		operation = arg
+	}
+	return operation
+}
+
+func isGcloudOperation(arg string) bool {
+	switch arg {
+	case "list", "describe", "read", "deploy", "submit", "delete", "update", "create":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetPlanAction(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    string
+	}{
+		{"aws", []string{"lambda", "get-function", "--function-name", "x"}, PlanActionRead},
+		{"aws", []string{"lambda", "list-versions-by-function"}, PlanActionRead},
+		{"aws", []string{"s3api", "head-bucket", "--bucket", "x"}, PlanActionRead},
+		{"aws", []string{"ecr", "describe-repositories"}, PlanActionRead},
+		{"aws", []string{"lambda", "create-function"}, PlanActionChange},
+		{"aws", []string{"lambda", "update-function-code"}, PlanActionChange},
+		{"aws", []string{"s3", "cp", "deployment.zip", "s3://bucket/key"}, PlanActionChange},
+		{"gcloud", []string{"run", "services", "describe", "hello", "--format", "json"}, PlanActionRead},
+		{"gcloud", []string{"functions", "list"}, PlanActionRead},
+		{"gcloud", []string{"logging", "read", "x"}, PlanActionRead},
+		{"gcloud", []string{"run", "deploy", "hello", "--source", "."}, PlanActionChange},
+		{"gcloud", []string{"secrets", "create", "list"}, PlanActionChange},
+		{"docker", []string{"build", "--quiet", "-t", "x", "."}, PlanActionChange},
+		{"docker", []string{"buildx", "build", "--quiet", "--load", "-t", "x", "."}, PlanActionChange},
+		{"docker", []string{"buildx", "version"}, PlanActionLocal},
+		{"docker", []string{"tag", "x", "y"}, PlanActionChange},
+		{"docker", []string{"login", "--password-stdin", "registry"}, PlanActionChange},
+		{"docker", []string{"push", "y"}, PlanActionChange},
+		{"docker", []string{"run", "--rm", "image"}, PlanActionLocal},
+		{"go", []string{"build", "-o", "bootstrap"}, PlanActionLocal},
+		{"git", []string{"rev-parse", "HEAD"}, PlanActionLocal},
+	}
+	for _, test := range tests {
+		t.Run(FormatCommand(test.command, test.args), func(t *testing.T) {
+			if got := getPlanAction(&Command{Name: test.command, Args: test.args}); got != test.want {
+				t.Errorf("getPlanAction() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPlanRunner(t *testing.T) {
+	inner := NewFakeRunner(
+		&FakeResponse{Command: "aws", Args: []string{"iam", "get-role", "--role-name", "x"}, ExitCode: 254},
+		&FakeResponse{Command: "go", Args: []string{"build"}},
+	)
+	plan := NewPlanRunner(inner)
+
+	if _, err := plan.Run(&Command{Name: "aws", Args: []string{"iam", "get-role", "--role-name", "x"}}); !IsExitCode(err, 254) {
+		t.Errorf("read: err = %v, want the inner runner's error", err)
+	}
+	output, err := plan.Run(&Command{Name: "aws", Args: []string{"iam", "create-role", "--role-name", "x"}})
+	if err != nil || string(output) != plannedOutputs["create-role"] {
+		t.Errorf("change = %q, %v; want the planned output", output, err)
+	}
+	output, err = plan.Run(&Command{Name: "aws", Args: []string{"lambda", "tag-resource"}})
+	if err != nil || string(output) != "{}" {
+		t.Errorf("change = %q, %v; want {}", output, err)
+	}
+	if _, err := plan.Run(&Command{Name: "go", Args: []string{"build"}}); err != nil {
+		t.Errorf("local: err = %v", err)
+	}
+
+	// Only the read and the local command reach the inner runner
+	if len(inner.Calls) != 2 {
+		t.Errorf("inner runner calls = %d, want 2", len(inner.Calls))
+	}
+	actions := []string{}
+	for _, step := range plan.Plan.Steps {
+		actions = append(actions, step.Action)
+	}
+	want := []string{PlanActionRead, PlanActionChange, PlanActionChange, PlanActionLocal}
+	if !reflect.DeepEqual(actions, want) || plan.Plan.Changes() != 2 {
+		t.Errorf("actions = %v (%d changes), want %v", actions, plan.Plan.Changes(), want)
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"lambda", "get-function"}, "aws lambda get-function"},
+		{[]string{"--query", "Role.Arn"}, "aws --query Role.Arn"},
+		{[]string{"--description", "a b"}, "aws --description 'a b'"},
+		{[]string{"--payload", ""}, "aws --payload ''"},
+		{[]string{"--name", "it's"}, `aws --name 'it'\''s'`},
+	}
+	for _, test := range tests {
+		if got := FormatCommand("aws", test.args); got != test.want {
+			t.Errorf("FormatCommand(%v) = %s, want %s", test.args, got, test.want)
+		}
+	}
+}
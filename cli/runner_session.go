@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// RedactedOutput replaces the output of secret commands in recorded sessions
+const RedactedOutput = "<redacted>"
+
+// Session is a sequence of commands and their results,
+// stored as JSON so that it can be replayed later
+type Session struct {
+	Interactions []*FakeResponse `json:"interactions"`
+}
+
+// RecordingRunner runs commands with another runner, and
+// writes each one (and its result) to a session file
+type RecordingRunner struct {
+	Runner  Runner
+	Path    string
+	session Session
+}
+
+func NewRecordingRunner(inner Runner, path string) *RecordingRunner {
+	return &RecordingRunner{
+		Runner: inner,
+		Path:   path,
+	}
+}
+
+func (r *RecordingRunner) Run(cmd *Command) ([]byte, error) {
+	output, err := r.Runner.Run(cmd)
+	interaction := &FakeResponse{
+		Command: cmd.Name,
+		Args:    cmd.Args,
+		Stdout:  string(output),
+	}
+	if cmd.Secret && len(output) > 0 {
+		interaction.Stdout = RedactedOutput
+	}
+	if err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) {
+			interaction.ExitCode = cmdErr.ExitCode
+			interaction.Stderr = string(cmdErr.Stderr)
+		} else {
+			// The command could not be started (e.g. it is not installed)
+			interaction.ExitCode = 127
+			interaction.Stderr = err.Error()
+		}
+	}
+
+	// The session is written after every command, so that it
+	// is kept even if kettle exits early
+	r.session.Interactions = append(r.session.Interactions, interaction)
+	if writeErr := writeSession(r.Path, &r.session); writeErr != nil {
+		return nil, writeErr
+	}
+	return output, err
+}
+
+// NewReplayRunner creates a runner that returns the results
+// stored in a session file
+func NewReplayRunner(path string) (*FakeRunner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	return NewFakeRunner(session.Interactions...), nil
+}
+
+func writeSession(path string, session *Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	inner := NewFakeRunner(
+		&FakeResponse{Command: "aws", Args: []string{"sts", "get-caller-identity"}, Stdout: `{"Account": "123"}`},
+		&FakeResponse{Command: "aws", Args: []string{"ecr", "get-login-password"}, Stdout: "password"},
+		&FakeResponse{Command: "aws", Args: []string{"lambda", "get-function"}, ExitCode: 254, Stderr: "not found"},
+	)
+	path := filepath.Join(t.TempDir(), "session.json")
+	recorder := NewRecordingRunner(inner, path)
+
+	commands := []*Command{
+		{Name: "aws", Args: []string{"sts", "get-caller-identity"}},
+		{Name: "aws", Args: []string{"ecr", "get-login-password"}, Secret: true},
+		{Name: "aws", Args: []string{"lambda", "get-function"}},
+	}
+	for _, cmd := range commands {
+		recorder.Run(cmd)
+	}
+
+	replay, err := NewReplayRunner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cmd      *Command
+		want     string
+		exitCode int
+	}{
+		{commands[0], `{"Account": "123"}`, 0},
+		// The output of secret commands is not recorded
+		{commands[1], RedactedOutput, 0},
+		{commands[2], "", 254},
+	}
+	for _, test := range tests {
+		output, err := replay.Run(test.cmd)
+		if test.exitCode != 0 {
+			var cmdErr *CommandError
+			if !errors.As(err, &cmdErr) || cmdErr.ExitCode != test.exitCode || string(cmdErr.Stderr) != "not found" {
+				t.Errorf("%v: err = %v, want exit code %d", test.cmd.Args, err, test.exitCode)
+			}
+			continue
+		}
+		if err != nil || string(output) != test.want {
+			t.Errorf("%v = %q, %v; want %q", test.cmd.Args, output, err, test.want)
+		}
+	}
+}
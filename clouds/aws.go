@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os/exec"
 
+	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/clouds/aws"
 	"github.com/operatorai/kettle-cli/settings"
 )
@@ -14,6 +15,8 @@ func (AmazonWebServices) GetService(deploymentType string) (Service, error) {
 	switch deploymentType {
 	case "lambda":
 		return aws.AWSLambdaFunction{}, nil
+	case "lambda-container":
+		return aws.AWSLambdaContainer{}, nil
 	}
 	return nil, fmt.Errorf("unimplemented service: %s", deploymentType)
 }
@@ -21,7 +24,10 @@ func (AmazonWebServices) GetService(deploymentType string) (Service, error) {
 func (AmazonWebServices) Setup(stg *settings.Settings, overwrite bool) error {
 	_, err := exec.LookPath("aws")
 	if err != nil {
-		return fmt.Errorf("please install the aws cli: %s", err)
+		return &cli.MissingToolError{
+			Tool: "the aws cli",
+			Err:  err,
+		}
 	}
 	if stg.AWS == nil {
 		stg.AWS = &settings.AWSSettings{}
@@ -32,5 +38,8 @@ func (AmazonWebServices) Setup(stg *settings.Settings, overwrite bool) error {
 	if err := aws.SetDeploymentRegion(stg.AWS, overwrite); err != nil {
 		return err
 	}
+	if err := aws.SetEnvironments(stg.AWS, overwrite); err != nil {
+		return err
+	}
 	return nil
 }
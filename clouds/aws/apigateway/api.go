@@ -2,6 +2,7 @@ package apigateway
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/settings"
@@ -34,7 +35,7 @@ func SetRestApiID(stg *settings.Settings, overwrite bool) error {
 	} else {
 		// Allow the user to create a new REST API
 		// if the operator one doesn't alredy exist
-		restApiID, err = cli.PromptForValue("AWS REST API", apis, !operatorApiExists)
+		restApiID, err = cli.PromptForValue("aws.rest_api", "AWS REST API", apis, !operatorApiExists)
 		if err != nil {
 			return err
 		}
@@ -50,13 +51,13 @@ func SetRestApiID(stg *settings.Settings, overwrite bool) error {
 	return nil
 }
 
-func Deploy(stg *settings.Settings) error {
+func Deploy(stg *settings.Settings, stage string) error {
 	return cli.Execute("aws", []string{
 		"apigateway",
 		"create-deployment",
 		"--rest-api-id", stg.AWS.RestApiID,
-		"--stage-name", "prod", // @TODO add support for different stages
-	}, "Deploying the REST API")
+		"--stage-name", stage,
+	}, fmt.Sprintf("Deploying the REST API to the %s stage", stage))
 }
 
 func getRestApis() (map[string]string, bool, error) {
@@ -65,7 +66,7 @@ func getRestApis() (map[string]string, bool, error) {
 		"get-rest-apis",
 	}, "Collecting available REST APIs")
 	if err != nil {
-		if err.Error() == "exit status 254" {
+		if cli.IsExitCode(err, 254) {
 			return map[string]string{}, false, nil
 		}
 		return nil, false, err
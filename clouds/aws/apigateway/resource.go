@@ -13,24 +13,26 @@ type RestApiResource struct {
 	Path          string
 	ID            string
 	HasPostMethod bool
+	Methods       []string
 }
 
-func SetResourceID(resources []*RestApiResource, cfg *config.Config, stg *settings.Settings) error {
-	if cfg.Config.AWS.RestApiResourceID != "" {
+// SetResourceID creates (or finds) the /<pathPart> resource in the API
+func SetResourceID(resources []*RestApiResource, pathPart string, deployment *config.AWSDeployment, stg *settings.Settings) error {
+	if deployment.RestApiResourceID != "" {
 		return nil
 	}
 
 	// Look for existing resource ID
-	restApiResource := getResourceWithPath(resources, cfg.ProjectName)
+	restApiResource := getResourceWithPath(resources, pathPart)
 	if restApiResource == nil {
 		// Not found: create a resource in the API
 		output, err := cli.ExecuteWithResult("aws", []string{
 			"apigateway",
 			"create-resource",
 			"--rest-api-id", stg.AWS.RestApiID,
-			"--path-part", cfg.ProjectName,
+			"--path-part", pathPart,
 			"--parent-id", stg.AWS.RestApiRootID,
-		}, fmt.Sprintf("Creating /%s API resource", cfg.ProjectName))
+		}, fmt.Sprintf("Creating /%s API resource", pathPart))
 		if err != nil {
 			return err
 		}
@@ -42,15 +44,15 @@ func SetResourceID(resources []*RestApiResource, cfg *config.Config, stg *settin
 			return err
 		}
 		restApiResource = &RestApiResource{
-			Path:          cfg.ProjectName,
+			Path:          pathPart,
 			ID:            result.ID,
 			HasPostMethod: false,
 		}
 	}
 
-	cfg.Config.AWS.RestApiResourceID = restApiResource.ID
+	deployment.RestApiResourceID = restApiResource.ID
 	// Check for POST method
-	if err := addResourcePOSTMethod(restApiResource, stg.AWS.RestApiID, cfg.Config.AWS.RestApiResourceID); err != nil {
+	if err := addResourcePOSTMethod(restApiResource, stg.AWS.RestApiID, deployment.RestApiResourceID); err != nil {
 		return err
 	}
 	return nil
@@ -61,8 +63,13 @@ func addResourcePOSTMethod(resource *RestApiResource, apiID, resourceID string)
 		return nil
 	}
 
+	apiKeyRequired, err := cli.PromptToConfirm("aws.api_key_required", "Require an API key to call the URL")
+	if err != nil {
+		return err
+	}
+
 	apiKeySetting := "--no-api-key-required"
-	if cli.PromptToConfirm("Require an API key to call the URL") {
+	if apiKeyRequired {
 		apiKeySetting = "--api-key-required"
 		// Note: if an api key is required, then there is more set up to do:
 
@@ -76,7 +83,7 @@ func addResourcePOSTMethod(resource *RestApiResource, apiID, resourceID string)
 	}
 
 	// Create the method
-	err := cli.Execute("aws", []string{
+	err = cli.Execute("aws", []string{
 		"apigateway",
 		"put-method",
 		"--rest-api-id", apiID,
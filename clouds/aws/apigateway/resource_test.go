@@ -0,0 +1,67 @@
+package apigateway
+
+import (
+	"os"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// useFakeRunner swaps the command runner for a fake until the test ends
+func useFakeRunner(t *testing.T) *cli.FakeRunner {
+	fake := cli.NewFakeRunner()
+	previous := cli.GetRunner()
+	cli.SetRunner(fake)
+	t.Cleanup(func() {
+		cli.SetRunner(previous)
+		if remaining := fake.Remaining(); len(remaining) != 0 {
+			t.Errorf("%d expected commands were not run", len(remaining))
+		}
+	})
+	return fake
+}
+
+func TestSetResourceID(t *testing.T) {
+	stg := &settings.Settings{AWS: &settings.AWSSettings{RestApiID: "api", RestApiRootID: "root"}}
+	resources := []*RestApiResource{
+		{Path: "/hello", ID: "r1", HasPostMethod: true},
+	}
+
+	// The resource of a function without an environment is found by its path
+	useFakeRunner(t)
+	deployment := &config.AWSDeployment{}
+	if err := SetResourceID(resources, "hello", deployment, stg); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.RestApiResourceID != "r1" {
+		t.Errorf("RestApiResourceID = %s, want r1", deployment.RestApiResourceID)
+	}
+
+	// An environment's function gets its own resource, with a POST method
+	os.Setenv(cli.AnswerEnvVar("aws.api_key_required"), "no")
+	defer os.Unsetenv(cli.AnswerEnvVar("aws.api_key_required"))
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "apigateway", "create-resource", "--rest-api-id", "api", "--path-part", "hello-dev", "--parent-id", "root").Stdout = `{"id": "r2"}`
+	fake.Expect("aws", "apigateway", "put-method", "--rest-api-id", "api", "--resource-id", "r2", "--http-method", "POST", "--authorization-type", "NONE", "--no-api-key-required")
+	fake.Expect("aws", "apigateway", "put-method-response", "--rest-api-id", "api", "--resource-id", "r2", "--http-method", "POST", "--status-code", "200", "--response-models", "application/json=Empty")
+
+	deployment = &config.AWSDeployment{}
+	if err := SetResourceID(resources, "hello-dev", deployment, stg); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.RestApiResourceID != "r2" {
+		t.Errorf("RestApiResourceID = %s, want r2", deployment.RestApiResourceID)
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	stg := &settings.Settings{AWS: &settings.AWSSettings{RestApiID: "api"}}
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "apigateway", "create-deployment", "--rest-api-id", "api", "--stage-name", "dev")
+
+	if err := Deploy(stg, "dev"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,7 @@ package apigateway
 
 import (
 	"encoding/json"
+	"sort"
 	"strings"
 
 	"github.com/operatorai/kettle-cli/cli"
@@ -50,3 +51,41 @@ func getResourceWithPath(resources []*RestApiResource, pathPart string) *RestApi
 	}
 	return nil
 }
+
+// GetResource returns nil if the resource does not exist
+func GetResource(stg *settings.Settings, resourceID string) (*RestApiResource, error) {
+	output, err := cli.ExecuteWithResult("aws", []string{
+		"apigateway",
+		"get-resource",
+		"--rest-api-id", stg.AWS.RestApiID,
+		"--resource-id", resourceID,
+	}, "Querying for API resource")
+	if err != nil {
+		if cli.IsExitCode(err, 254) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result struct {
+		Path            string                 `json:"path"`
+		ID              string                 `json:"id"`
+		ResourceMethods map[string]interface{} `json:"resourceMethods"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, err
+	}
+
+	resource := &RestApiResource{
+		Path: result.Path,
+		ID:   result.ID,
+	}
+	for method := range result.ResourceMethods {
+		resource.Methods = append(resource.Methods, method)
+		if method == "POST" {
+			resource.HasPostMethod = true
+		}
+	}
+	sort.Strings(resource.Methods)
+	return resource, nil
+}
@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// archiveModTime is the modification time of every file in a deployment
+// archive, so that the same code always produces an identical archive
+var archiveModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type archiveFile struct {
+	sourcePath string
+	mode       os.FileMode
+}
+
+// deploymentArchive collects files from different directories
+// and writes them to a zip file in a deterministic order
+type deploymentArchive struct {
+	files map[string]*archiveFile
+}
+
+func newDeploymentArchive() *deploymentArchive {
+	return &deploymentArchive{
+		files: map[string]*archiveFile{},
+	}
+}
+
+// addFile adds a file at the given path in the archive; files added
+// later replace earlier ones with the same path
+func (a *deploymentArchive) addFile(archivePath, sourcePath string, mode os.FileMode) {
+	// Only the executable bit is kept, so that the archive
+	// does not depend on the umask of the machine that built it
+	fileMode := os.FileMode(0644)
+	if mode&0111 != 0 {
+		fileMode = 0755
+	}
+	a.files[filepath.ToSlash(archivePath)] = &archiveFile{
+		sourcePath: sourcePath,
+		mode:       fileMode,
+	}
+}
+
+// addDirectory adds the contents of a directory to the root of the archive;
+// skip is called with paths relative to the directory
+func (a *deploymentArchive) addDirectory(directory string, skip func(relativePath string, isDir bool) bool) error {
+	return filepath.Walk(directory, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+
+		// Symbolic links are followed to the files they point to
+		if info.Mode()&os.ModeSymlink != 0 {
+			info, err = os.Stat(filePath)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				// Linked directories are skipped, to avoid cycles
+				return nil
+			}
+		}
+
+		if skip != nil && skip(filepath.ToSlash(relativePath), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		a.addFile(relativePath, filePath, info.Mode())
+		return nil
+	})
+}
+
+// write creates the zip file, with its files sorted by path
+func (a *deploymentArchive) write(archivePath string) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	archivePaths := []string{}
+	for archivePath := range a.files {
+		archivePaths = append(archivePaths, archivePath)
+	}
+	sort.Strings(archivePaths)
+
+	w := zip.NewWriter(f)
+	for _, archivePath := range archivePaths {
+		if err := writeArchiveFile(w, archivePath, a.files[archivePath]); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeArchiveFile(w *zip.Writer, archivePath string, file *archiveFile) error {
+	header := &zip.FileHeader{
+		Name:     archivePath,
+		Method:   zip.Deflate,
+		Modified: archiveModTime,
+	}
+	header.SetMode(file.mode)
+
+	writer, err := w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	source, err := os.Open(file.sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	_, err = io.Copy(writer, source)
+	return err
+}
@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	directory := t.TempDir()
+	for name, contents := range files {
+		filePath := filepath.Join(directory, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func writeTestArchive(t *testing.T, directory string, skip func(string, bool) bool) string {
+	t.Helper()
+	archive := newDeploymentArchive()
+	if err := archive.addDirectory(directory, skip); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "deployment.zip")
+	if err := archive.write(archivePath); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func readTestArchive(t *testing.T, archivePath string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDeploymentArchiveIsDeterministic(t *testing.T) {
+	files := map[string]string{
+		"main.py":         "def handler(event, context): pass",
+		"lib/util.py":     "x = 1",
+		"lib/data/a.json": "{}",
+	}
+	first := writeProject(t, files)
+	second := writeProject(t, files)
+
+	// Different modification times and permissions do not change the archive
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(second, "main.py"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(second, "lib/util.py"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	firstArchive := readTestArchive(t, writeTestArchive(t, first, nil))
+	if !bytes.Equal(firstArchive, readTestArchive(t, writeTestArchive(t, second, nil))) {
+		t.Errorf("the archives differ for the same files")
+	}
+
+	// A change to a file's contents does change it
+	if err := os.WriteFile(filepath.Join(second, "lib/util.py"), []byte("x = 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(firstArchive, readTestArchive(t, writeTestArchive(t, second, nil))) {
+		t.Errorf("the archive is unchanged after a file has changed")
+	}
+}
+
+func TestDeploymentArchiveContents(t *testing.T) {
+	directory := writeProject(t, map[string]string{
+		"main.py":          "",
+		"tests/test.py":    "",
+		"b/c.py":           "",
+		"a.py":             "",
+		"b/ignored.secret": "",
+	})
+	skip := func(relativePath string, isDir bool) bool {
+		return relativePath == "tests" || filepath.Ext(relativePath) == ".secret"
+	}
+
+	reader, err := zip.OpenReader(writeTestArchive(t, directory, skip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	names := []string{}
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		if !file.Modified.Equal(archiveModTime) {
+			t.Errorf("%s: modified = %s, want %s", file.Name, file.Modified, archiveModTime)
+		}
+		if file.Mode() != 0644 {
+			t.Errorf("%s: mode = %s, want 0644", file.Name, file.Mode())
+		}
+	}
+	want := []string{"a.py", "b/c.py", "main.py"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+}
+
+func TestAddFileMode(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want os.FileMode
+	}{
+		{0600, 0644},
+		{0664, 0644},
+		{0700, 0755},
+		{0775, 0755},
+	}
+	for _, test := range tests {
+		archive := newDeploymentArchive()
+		archive.addFile("bootstrap", "bootstrap", test.mode)
+		if got := archive.files["bootstrap"].mode; got != test.want {
+			t.Errorf("addFile(%o) mode = %o, want %o", test.mode, got, test.want)
+		}
+	}
+}
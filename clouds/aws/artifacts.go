@@ -0,0 +1,223 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+const (
+	// Archives larger than this cannot be uploaded with --zip-file
+	// https://docs.aws.amazon.com/lambda/latest/dg/gettingstarted-limits.html
+	directUploadLimit = 50 * 1024 * 1024
+
+	defaultArtifactRetention = 5
+)
+
+// lambdaCode is where a function's deployment archive is uploaded from:
+// either the local file, or an object in the artifact bucket
+type lambdaCode struct {
+	zipFile  string
+	s3Bucket string
+	s3Key    string
+}
+
+// updateArgs are the arguments to update-function-code
+func (c *lambdaCode) updateArgs() []string {
+	if c.s3Bucket != "" {
+		return []string{"--s3-bucket", c.s3Bucket, "--s3-key", c.s3Key}
+	}
+	return []string{"--zip-file", fmt.Sprintf("fileb://%s", c.zipFile)}
+}
+
+// createArgs are the arguments to create-function
+func (c *lambdaCode) createArgs() []string {
+	if c.s3Bucket != "" {
+		return []string{"--code", fmt.Sprintf("S3Bucket=%s,S3Key=%s", c.s3Bucket, c.s3Key)}
+	}
+	return []string{"--zip-file", fmt.Sprintf("fileb://%s", c.zipFile)}
+}
+
+// getLambdaCode returns the local archive if it can be uploaded directly, or
+// else uploads it to the artifact bucket (creating the bucket if needed)
+func getLambdaCode(deploymentArchive string, target *lambdaTarget, stg *settings.Settings, codeSha256 string) (*lambdaCode, error) {
+	info, err := os.Stat(deploymentArchive)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= directUploadLimit {
+		return &lambdaCode{zipFile: deploymentArchive}, nil
+	}
+
+	fmt.Printf("🪣  The archive is %d MB, so it is uploaded to S3\n", info.Size()/(1024*1024))
+	if err := setArtifactBucket(stg.AWS); err != nil {
+		return nil, err
+	}
+
+	// Archives are stored by their hash, so the same code is only uploaded once
+	code := &lambdaCode{
+		s3Bucket: stg.AWS.ArtifactBucket,
+		s3Key:    fmt.Sprintf("%s/%s.zip", target.name, getArtifactName(codeSha256)),
+	}
+	err = cli.Execute("aws", []string{
+		"s3",
+		"cp",
+		deploymentArchive,
+		fmt.Sprintf("s3://%s/%s", code.s3Bucket, code.s3Key),
+		"--only-show-errors",
+	}, "Uploading deployment archive to S3")
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// getArtifactName returns the hex of a (base64) CodeSha256, which can be used in an S3 key
+func getArtifactName(codeSha256 string) string {
+	sum, err := base64.StdEncoding.DecodeString(codeSha256)
+	if err != nil {
+		return strings.NewReplacer("/", "_", "+", "-", "=", "").Replace(codeSha256)
+	}
+	return hex.EncodeToString(sum)
+}
+
+// setArtifactBucket creates the artifact bucket in the account & region, if it
+// does not exist; its name is stored in the settings
+func setArtifactBucket(stg *settings.AWSSettings) error {
+	if stg.ArtifactBucket == "" {
+		if err := SetAccountID(stg, false); err != nil {
+			return err
+		}
+		stg.ArtifactBucket = fmt.Sprintf("kettle-artifacts-%s-%s", stg.AccountID, stg.DeploymentRegion)
+	}
+
+	err := cli.Execute("aws", []string{
+		"s3api",
+		"head-bucket",
+		"--bucket", stg.ArtifactBucket,
+	}, "Checking for the artifact bucket")
+	if err == nil {
+		return nil
+	}
+	var commandErr *cli.CommandError
+	if !errors.As(err, &commandErr) || !strings.Contains(string(commandErr.Stderr), "Not Found") {
+		return err
+	}
+
+	args := []string{
+		"s3api",
+		"create-bucket",
+		"--bucket", stg.ArtifactBucket,
+	}
+	if stg.DeploymentRegion != "us-east-1" {
+		// Buckets in us-east-1 cannot have a location constraint
+		args = append(args, "--create-bucket-configuration", fmt.Sprintf("LocationConstraint=%s", stg.DeploymentRegion))
+	}
+	if err := cli.Execute("aws", args, "Creating the artifact bucket"); err != nil {
+		return err
+	}
+	err = cli.Execute("aws", []string{
+		"s3api",
+		"put-public-access-block",
+		"--bucket", stg.ArtifactBucket,
+		"--public-access-block-configuration", "BlockPublicAcls=true,IgnorePublicAcls=true,BlockPublicPolicy=true,RestrictPublicBuckets=true",
+	}, "Blocking public access to the artifact bucket")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🪣  Created artifact bucket: %s\n", stg.ArtifactBucket)
+	return nil
+}
+
+type artifactObject struct {
+	Key          string `json:"Key"`
+	LastModified string `json:"LastModified"`
+}
+
+// removeOldArtifacts deletes a function's archives in the artifact bucket, apart
+// from the latest ones; published versions keep their own copy of the code,
+// so they can still be rolled back to
+func removeOldArtifacts(target *lambdaTarget, stg *settings.AWSSettings) error {
+	objects, err := listArtifacts(target, stg)
+	if err != nil {
+		return err
+	}
+
+	retention := stg.ArtifactRetention
+	if retention <= 0 {
+		retention = defaultArtifactRetention
+	}
+	if len(objects) <= retention {
+		return nil
+	}
+
+	// Timestamps are in ISO 8601, so they sort by time
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].LastModified > objects[j].LastModified
+	})
+	return deleteArtifacts(stg, objects[retention:])
+}
+
+// removeAllArtifacts deletes all of a function's archives in the artifact bucket
+func removeAllArtifacts(target *lambdaTarget, stg *settings.AWSSettings) error {
+	if stg.ArtifactBucket == "" {
+		return nil
+	}
+	objects, err := listArtifacts(target, stg)
+	if err != nil {
+		if cli.IsExitCode(err, 254) {
+			// The bucket has been deleted
+			return nil
+		}
+		return err
+	}
+	return deleteArtifacts(stg, objects)
+}
+
+func listArtifacts(target *lambdaTarget, stg *settings.AWSSettings) ([]*artifactObject, error) {
+	output, err := cli.ExecuteWithResult("aws", []string{
+		"s3api",
+		"list-objects-v2",
+		"--bucket", stg.ArtifactBucket,
+		"--prefix", fmt.Sprintf("%s/", target.name),
+		"--output", "json",
+	}, "Listing deployment archives in S3")
+	if err != nil {
+		return nil, err
+	}
+
+	// The output is empty if there are no objects
+	listing := struct {
+		Contents []*artifactObject `json:"Contents"`
+	}{}
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(output, &listing); err != nil {
+		return nil, err
+	}
+	return listing.Contents, nil
+}
+
+func deleteArtifacts(stg *settings.AWSSettings, objects []*artifactObject) error {
+	for _, object := range objects {
+		err := cli.Execute("aws", []string{
+			"s3",
+			"rm",
+			fmt.Sprintf("s3://%s/%s", stg.ArtifactBucket, object.Key),
+			"--only-show-errors",
+		}, "Removing old deployment archive from S3")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+func TestGetArtifactName(t *testing.T) {
+	tests := []struct {
+		codeSha256 string
+		want       string
+	}{
+		{"q83vEjRWeJq83vEjRWeJq83vEjRWeJq83vEjRWeJq80=", "abcdef123456789abcdef123456789abcdef123456789abcdef123456789abcd"},
+		{"not/base64+", "not_base64-"},
+	}
+	for _, test := range tests {
+		if got := getArtifactName(test.codeSha256); got != test.want {
+			t.Errorf("getArtifactName(%s) = %s, want %s", test.codeSha256, got, test.want)
+		}
+	}
+}
+
+// writeArchive writes a (sparse) file of the given size
+func writeArchive(t *testing.T, size int64) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "deployment.zip")
+	if err := os.WriteFile(archivePath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(archivePath, size); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestGetLambdaCode(t *testing.T) {
+	target := &lambdaTarget{name: "hello"}
+	stg := &settings.Settings{AWS: &settings.AWSSettings{
+		DeploymentRegion: "eu-west-1",
+		ArtifactBucket:   "kettle-artifacts",
+	}}
+
+	// Archives up to the limit are uploaded directly
+	archivePath := writeArchive(t, directUploadLimit)
+	code, err := getLambdaCode(archivePath, target, stg, "q83v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"--zip-file", "fileb://" + archivePath}; !reflect.DeepEqual(code.updateArgs(), want) {
+		t.Errorf("updateArgs() = %v, want %v", code.updateArgs(), want)
+	}
+
+	// Larger ones are uploaded to the artifact bucket, by their hash
+	archivePath = writeArchive(t, directUploadLimit+1)
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "s3api", "head-bucket", "--bucket", "kettle-artifacts")
+	fake.Expect("aws", "s3", "cp", archivePath, "s3://kettle-artifacts/hello/abcdef.zip", "--only-show-errors")
+
+	code, err = getLambdaCode(archivePath, target, stg, "q83v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"--s3-bucket", "kettle-artifacts", "--s3-key", "hello/abcdef.zip"}; !reflect.DeepEqual(code.updateArgs(), want) {
+		t.Errorf("updateArgs() = %v, want %v", code.updateArgs(), want)
+	}
+	if want := []string{"--code", "S3Bucket=kettle-artifacts,S3Key=hello/abcdef.zip"}; !reflect.DeepEqual(code.createArgs(), want) {
+		t.Errorf("createArgs() = %v, want %v", code.createArgs(), want)
+	}
+}
+
+func TestSetArtifactBucket(t *testing.T) {
+	stg := &settings.AWSSettings{
+		AccountID:        "123456789012",
+		DeploymentRegion: "eu-west-1",
+	}
+	fake := useFakeRunner(t)
+	notFound := fake.Expect("aws", "s3api", "head-bucket", "--bucket", "kettle-artifacts-123456789012-eu-west-1")
+	notFound.ExitCode = 254
+	notFound.Stderr = "An error occurred (404) when calling the HeadBucket operation: Not Found"
+	fake.Expect("aws", "s3api", "create-bucket",
+		"--bucket", "kettle-artifacts-123456789012-eu-west-1",
+		"--create-bucket-configuration", "LocationConstraint=eu-west-1",
+	)
+	fake.Expect("aws", "s3api", "put-public-access-block", "--bucket", "kettle-artifacts-123456789012-eu-west-1",
+		"--public-access-block-configuration", "*",
+	)
+
+	if err := setArtifactBucket(stg); err != nil {
+		t.Fatal(err)
+	}
+	if stg.ArtifactBucket != "kettle-artifacts-123456789012-eu-west-1" {
+		t.Errorf("ArtifactBucket = %s, want it to be named after the account and region", stg.ArtifactBucket)
+	}
+}
+
+func TestRemoveOldArtifacts(t *testing.T) {
+	target := &lambdaTarget{name: "hello"}
+	stg := &settings.AWSSettings{
+		ArtifactBucket:    "kettle-artifacts",
+		ArtifactRetention: 2,
+	}
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "s3api", "list-objects-v2", "--bucket", "kettle-artifacts", "--prefix", "hello/", "--output", "json").Stdout = `{
+		"Contents": [
+			{"Key": "hello/b.zip", "LastModified": "2024-01-02T00:00:00+00:00"},
+			{"Key": "hello/d.zip", "LastModified": "2024-01-04T00:00:00+00:00"},
+			{"Key": "hello/a.zip", "LastModified": "2024-01-01T00:00:00+00:00"},
+			{"Key": "hello/c.zip", "LastModified": "2024-01-03T00:00:00+00:00"}
+		]
+	}`
+	// Only the archives older than the latest two are removed
+	fake.Expect("aws", "s3", "rm", "s3://kettle-artifacts/hello/b.zip", "--only-show-errors")
+	fake.Expect("aws", "s3", "rm", "s3://kettle-artifacts/hello/a.zip", "--only-show-errors")
+
+	if err := removeOldArtifacts(target, stg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveOldArtifactsWithinRetention(t *testing.T) {
+	target := &lambdaTarget{name: "hello"}
+	stg := &settings.AWSSettings{ArtifactBucket: "kettle-artifacts"}
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "s3api", "list-objects-v2", "--bucket", "kettle-artifacts", "--prefix", "hello/", "--output", "json").Stdout = ""
+
+	if err := removeOldArtifacts(target, stg); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,196 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+// getFunctionVariables returns a function's environment variables, with
+// any secrets resolved from Secrets Manager or the SSM Parameter Store; they
+// are nil if kettle.json does not have an environment, so the function's
+// existing variables are left as they are
+func getFunctionVariables(directory string, cfg *config.Config, env string) (map[string]string, error) {
+	if cfg.Config.Environment == nil {
+		return nil, nil
+	}
+	resolved, err := cfg.ResolveEnvironment(directory, env)
+	if err != nil {
+		return nil, cli.NewConfigError(err)
+	}
+
+	variables := map[string]string{}
+	for key, value := range resolved.Variables {
+		variables[key] = value
+	}
+	for key, reference := range resolved.Secrets {
+		value, err := getSecretValue(reference)
+		if err != nil {
+			return nil, err
+		}
+		variables[key] = value
+	}
+	return variables, nil
+}
+
+func getSecretValue(reference string) (string, error) {
+	parts := strings.SplitN(reference, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", cli.NewConfigError(fmt.Errorf("invalid secret: %s (use secretsmanager:<secret-id> or ssm:<parameter-name>)", reference))
+	}
+
+	var args []string
+	switch parts[0] {
+	case "secretsmanager":
+		args = []string{
+			"secretsmanager",
+			"get-secret-value",
+			"--secret-id", parts[1],
+			"--query", "SecretString",
+			"--output", "text",
+		}
+	case "ssm":
+		args = []string{
+			"ssm",
+			"get-parameter",
+			"--name", parts[1],
+			"--with-decryption",
+			"--query", "Parameter.Value",
+			"--output", "text",
+		}
+	default:
+		return "", cli.NewConfigError(fmt.Errorf("unknown secret manager: %s (use secretsmanager or ssm)", parts[0]))
+	}
+
+	// The value is not recorded in sessions
+	output, err := cli.ExecuteSecret("aws", args, fmt.Sprintf("Reading secret: %s", reference))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+// writeEnvironmentFile writes the variables to a temporary file, so that
+// secret values are not visible in the arguments of the aws cli
+func writeEnvironmentFile(variables map[string]string) (string, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"Variables": variables,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Temp files are only readable by the current user
+	f, err := ioutil.TempFile("", "kettle-environment*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// getFunctionResources returns the resources of a function; Lambda functions
+// scale with one request per instance and their CPU is set by their memory
+func getFunctionResources(cfg *config.Config, env string) (*config.Resources, error) {
+	resources, err := cfg.ResolveResources(env)
+	if err != nil {
+		return nil, cli.NewConfigError(err)
+	}
+	switch {
+	case resources.CPU != "":
+		return nil, cli.NewConfigError(fmt.Errorf("cpu is not supported for AWS Lambda functions (set memory_mb instead)"))
+	case resources.Concurrency != 0:
+		return nil, cli.NewConfigError(fmt.Errorf("concurrency is not supported for AWS Lambda functions (set max_instances instead)"))
+	case resources.MinInstances != nil:
+		return nil, cli.NewConfigError(fmt.Errorf("min_instances is not supported for AWS Lambda functions"))
+	}
+	return resources, nil
+}
+
+// resourceArgs returns the arguments that set a function's memory & timeout
+func resourceArgs(resources *config.Resources) []string {
+	args := []string{}
+	if resources.MemoryMB != 0 {
+		args = append(args, "--memory-size", strconv.Itoa(resources.MemoryMB))
+	}
+	if resources.TimeoutSeconds != 0 {
+		args = append(args, "--timeout", strconv.Itoa(resources.TimeoutSeconds))
+	}
+	return args
+}
+
+// updateFunctionConfiguration reconciles a function's environment variables
+// and resources with kettle.json, and returns true if they have changed; nil
+// variables are not reconciled
+func updateFunctionConfiguration(function *lambdaFunction, target *lambdaTarget, variables map[string]string, resources *config.Resources) (bool, error) {
+	args := []string{}
+	if variables != nil && !variablesEqual(function.Configuration.Environment.Variables, variables) {
+		environmentFile, err := writeEnvironmentFile(variables)
+		if err != nil {
+			return false, err
+		}
+		defer os.Remove(environmentFile)
+		args = append(args, "--environment", fmt.Sprintf("file://%s", environmentFile))
+	}
+	if resources.MemoryMB != 0 && resources.MemoryMB != function.Configuration.MemorySize {
+		args = append(args, "--memory-size", strconv.Itoa(resources.MemoryMB))
+	}
+	if resources.TimeoutSeconds != 0 && resources.TimeoutSeconds != function.Configuration.Timeout {
+		args = append(args, "--timeout", strconv.Itoa(resources.TimeoutSeconds))
+	}
+
+	changed := len(args) > 0
+	if changed {
+		err := cli.Execute("aws", append([]string{
+			"lambda",
+			"update-function-configuration",
+			"--function-name", target.name,
+		}, args...), "Updating lambda function configuration")
+		if err != nil {
+			return false, err
+		}
+		if err := waitForLambda("function-updated", target); err != nil {
+			return false, err
+		}
+	}
+	return changed, setReservedConcurrency(function, target, resources)
+}
+
+// setReservedConcurrency limits the number of instances of a function, if it has changed
+func setReservedConcurrency(function *lambdaFunction, target *lambdaTarget, resources *config.Resources) error {
+	if resources.MaxInstances == nil {
+		return nil
+	}
+	current := function.Concurrency.ReservedConcurrentExecutions
+	if current != nil && *current == *resources.MaxInstances {
+		return nil
+	}
+	return cli.Execute("aws", []string{
+		"lambda",
+		"put-function-concurrency",
+		"--function-name", target.name,
+		"--reserved-concurrent-executions", strconv.Itoa(*resources.MaxInstances),
+	}, "Setting lambda function concurrency")
+}
+
+func variablesEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
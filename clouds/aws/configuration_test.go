@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/config"
+)
+
+func TestGetFunctionVariables(t *testing.T) {
+	cfg := &config.Config{}
+	variables, err := getFunctionVariables(t.TempDir(), cfg, "")
+	if err != nil || variables != nil {
+		t.Errorf("getFunctionVariables() without an environment = %v, %v; want nil", variables, err)
+	}
+
+	cfg.Config.Environment = &config.Environment{}
+	variables, err = getFunctionVariables(t.TempDir(), cfg, "")
+	if err != nil || variables == nil || len(variables) != 0 {
+		t.Errorf("getFunctionVariables() with an empty environment = %v, %v; want no variables", variables, err)
+	}
+}
+
+func TestUpdateFunctionConfiguration(t *testing.T) {
+	target := &lambdaTarget{name: "hello"}
+	function := &lambdaFunction{}
+	function.Configuration.Environment.Variables = map[string]string{"SET": "in the console"}
+
+	// Without an environment in kettle.json, the function's variables are left as they are
+	useFakeRunner(t)
+	changed, err := updateFunctionConfiguration(function, target, nil, &config.Resources{})
+	if err != nil || changed {
+		t.Fatalf("updateFunctionConfiguration() = %t, %v; want no change", changed, err)
+	}
+
+	// An empty environment clears them
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "lambda", "update-function-configuration", "--function-name", "hello", "--environment", "*")
+	fake.Expect("aws", "lambda", "wait", "function-updated", "--function-name", "hello")
+	changed, err = updateFunctionConfiguration(function, target, map[string]string{}, &config.Resources{})
+	if err != nil || !changed {
+		t.Fatalf("updateFunctionConfiguration() = %t, %v; want a change", changed, err)
+	}
+}
+
+func TestGetFunctionResources(t *testing.T) {
+	one := 1
+	tests := []struct {
+		name      string
+		resources *config.Resources
+		wantArgs  []string
+		wantErr   bool
+	}{
+		{"memory and timeout", &config.Resources{MemoryMB: 512, TimeoutSeconds: 30}, []string{"--memory-size", "512", "--timeout", "30"}, false},
+		{"none", &config.Resources{}, []string{}, false},
+		{"cpu", &config.Resources{CPU: "1"}, nil, true},
+		{"concurrency", &config.Resources{Concurrency: 10}, nil, true},
+		{"min instances", &config.Resources{MinInstances: &one}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Config.Resources = test.resources
+			resources, err := getFunctionResources(cfg, "")
+			if test.wantErr {
+				if err == nil {
+					t.Error("getFunctionResources() did not return an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resourceArgs(resources); !reflect.DeepEqual(got, test.wantArgs) {
+				t.Errorf("resourceArgs() = %v, want %v", got, test.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// AWSLambdaContainer is a Lambda function that is deployed from a container
+// image, which is built from the project's Dockerfile and pushed to ECR;
+// images can be up to 10GB, instead of the 250MB limit of zip archives.
+// Apart from deploying, it is the same as a Lambda function
+// https://docs.aws.amazon.com/lambda/latest/dg/images-create.html
+type AWSLambdaContainer struct {
+	AWSLambdaFunction
+}
+
+func (AWSLambdaContainer) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) (*config.Deployment, error) {
+	stg, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+	if _, err := os.Stat(path.Join(directory, "Dockerfile")); err != nil {
+		return nil, cli.NewConfigError(fmt.Errorf("lambda-container projects need a Dockerfile: %w", err))
+	}
+
+	target := newDeploymentTarget(cfg, env)
+	fmt.Printf("🚢  Deploying: %s as an AWS Lambda container\n", target.name)
+
+	// The image is built for the function's instruction set
+	function, err := getLambdaFunction(target.name)
+	if err != nil {
+		return nil, err
+	}
+	if function != nil && function.Configuration.PackageType != "Image" {
+		return nil, cli.NewConfigError(fmt.Errorf("%s was deployed from a zip archive; run kettle destroy before deploying it as a container", target.name))
+	}
+	architecture, err := getLambdaArchitecture(cfg, function)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := getFunctionVariables(directory, cfg, env)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := getFunctionResources(cfg, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the image, and push it to the function's repository
+	repositoryURI, err := getOrCreateRepository(target)
+	if err != nil {
+		return nil, err
+	}
+	imageURI, imageID, err := buildAndPushImage(directory, repositoryURI, architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := true
+	if function != nil {
+		// Update the function with the new image, unless it has not changed
+		architectureChanged := architecture != getFunctionArchitecture(function)
+		codeChanged := function.Code.ImageUri != imageURI || architectureChanged || settings.ForceDeploy
+		if codeChanged {
+			err := cli.Execute("aws", []string{
+				"lambda",
+				"update-function-code",
+				"--function-name", target.name,
+				"--architectures", architecture,
+				"--image-uri", imageURI,
+			}, "Updating lambda function image")
+			if err != nil {
+				return nil, err
+			}
+			if err := waitForLambda("function-updated", target); err != nil {
+				return nil, err
+			}
+		} else {
+			fmt.Printf("⏭  No changes to the image (%s); use --force to re-deploy\n", imageURI)
+		}
+
+		// Update the function's configuration, if it has changed
+		configurationChanged, err := updateFunctionConfiguration(function, target, variables, resources)
+		if err != nil {
+			return nil, err
+		}
+		changed = codeChanged || configurationChanged
+	} else {
+		// Create the Lambda function; its handler is the image's CMD
+		err := createFunction(target, stg, variables, resources,
+			"--architectures", architecture,
+			"--package-type", "Image",
+			"--code", fmt.Sprintf("ImageUri=%s", imageURI),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := setReservedConcurrency(&lambdaFunction{}, target, resources); err != nil {
+			return nil, err
+		}
+		if err := waitForLambda("function-active", target); err != nil {
+			return nil, err
+		}
+	}
+
+	target.deployment.ImageURI = imageURI
+	return releaseLambda(target, stg, function == nil, changed, "", imageID)
+}
+
+// Destroy deletes the function (in the same way as for zip functions)
+// and then its ECR repository, which only has the function's images
+func (AWSLambdaContainer) Destroy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
+	// The function's state is removed from kettle.json when it is destroyed
+	target := newLambdaTarget(cfg, env)
+	if err := (AWSLambdaFunction{}).Destroy(directory, cfg, stg, env); err != nil {
+		return err
+	}
+
+	err := cli.Execute("aws", []string{
+		"ecr",
+		"delete-repository",
+		"--repository-name", strings.ToLower(target.name),
+		"--force",
+	}, "Deleting ECR repository")
+	if err != nil && !cli.IsExitCode(err, 254) {
+		return err
+	}
+	return nil
+}
+
+type ecrRepository struct {
+	RepositoryURI string `json:"repositoryUri"`
+}
+
+// getOrCreateRepository returns the URI of the function's ECR repository,
+// and creates the repository if it does not exist
+func getOrCreateRepository(target *lambdaTarget) (string, error) {
+	// Repository names must be lowercase
+	name := strings.ToLower(target.name)
+	output, err := cli.ExecuteWithResult("aws", []string{
+		"ecr",
+		"describe-repositories",
+		"--repository-names", name,
+		"--output", "json",
+	}, "Checking for ECR repository")
+	if err == nil {
+		repositories := struct {
+			Repositories []*ecrRepository `json:"repositories"`
+		}{}
+		if err := json.Unmarshal(output, &repositories); err != nil {
+			return "", err
+		}
+		if len(repositories.Repositories) > 0 {
+			return repositories.Repositories[0].RepositoryURI, nil
+		}
+	} else if !cli.IsExitCode(err, 254) {
+		return "", err
+	}
+
+	output, err = cli.ExecuteWithResult("aws", []string{
+		"ecr",
+		"create-repository",
+		"--repository-name", name,
+		"--image-scanning-configuration", "scanOnPush=true",
+		"--output", "json",
+	}, "Creating ECR repository")
+	if err != nil {
+		return "", err
+	}
+	repository := struct {
+		Repository *ecrRepository `json:"repository"`
+	}{}
+	if err := json.Unmarshal(output, &repository); err != nil {
+		return "", err
+	}
+	if repository.Repository == nil {
+		return "", fmt.Errorf("could not create the ECR repository: %s", name)
+	}
+	fmt.Printf("📦  Created ECR repository: %s\n", repository.Repository.RepositoryURI)
+	return repository.Repository.RepositoryURI, nil
+}
+
+// buildAndPushImage builds the project's Dockerfile and pushes it to the repository. The
+// image is tagged with its ID, so that an unchanged image (e.g. when all of the build's
+// layers are cached) has the same URI; it returns the image's URI and ID
+func buildAndPushImage(directory, repositoryURI, architecture string) (string, string, error) {
+	buildTag := fmt.Sprintf("%s:kettle-build", repositoryURI)
+	// With --quiet, the build only writes the image's ID
+	args := []string{
+		"build",
+		"--quiet",
+		"--platform", getDockerPlatform(architecture),
+		"-t", buildTag,
+		directory,
+	}
+	if hasBuildx() {
+		// Lambda does not support the image indexes that buildx creates for attestations,
+		// and buildx only adds the image to the local images with --load
+		args = append([]string{"buildx", "build", "--load", "--provenance=false"}, args[1:]...)
+	}
+	output, err := cli.ExecuteWithResult("docker", args, "Building container image")
+	if err != nil {
+		return "", "", err
+	}
+
+	imageID := strings.TrimSpace(string(output))
+	tag := strings.TrimPrefix(imageID, "sha256:")
+	if len(tag) > 12 {
+		tag = tag[:12]
+	}
+	imageURI := fmt.Sprintf("%s:%s", repositoryURI, tag)
+	err = cli.Execute("docker", []string{
+		"tag",
+		buildTag,
+		imageURI,
+	}, "Tagging container image")
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := loginToRegistry(repositoryURI); err != nil {
+		return "", "", err
+	}
+	err = cli.Execute("docker", []string{
+		"push",
+		imageURI,
+	}, "Pushing container image")
+	if err != nil {
+		return "", "", err
+	}
+	return imageURI, imageID, nil
+}
+
+// hasBuildx returns true if the docker buildx plugin is installed; the legacy
+// builder does not create attestations, or support the flags that turn them off
+func hasBuildx() bool {
+	_, err := cli.ExecuteWithResult("docker", []string{
+		"buildx",
+		"version",
+	}, "Checking for docker buildx")
+	return err == nil
+}
+
+// loginToRegistry logs docker in to the repository's ECR registry; the password
+// is passed on stdin, so that it is not in the command's arguments, and is
+// not recorded in sessions
+func loginToRegistry(repositoryURI string) error {
+	password, err := cli.ExecuteSecret("aws", []string{
+		"ecr",
+		"get-login-password",
+	}, "Getting ECR login password")
+	if err != nil {
+		return err
+	}
+
+	registry := strings.Split(repositoryURI, "/")[0]
+	return cli.ExecuteWithInput("docker", []string{
+		"login",
+		"--username", "AWS",
+		"--password-stdin",
+		registry,
+	}, password, "Logging in to ECR")
+}
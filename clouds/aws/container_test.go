@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestBuildAndPushImage(t *testing.T) {
+	const repository = "123.dkr.ecr.eu-west-1.amazonaws.com/hello"
+	const imageID = "sha256:0123456789abcdef0123"
+	tests := []struct {
+		name   string
+		buildx bool
+		build  []string
+	}{
+		{
+			"buildx",
+			true,
+			[]string{"buildx", "build", "--load", "--provenance=false", "--quiet", "--platform", "linux/arm64", "-t", repository + ":kettle-build", "app"},
+		},
+		{
+			// The legacy builder does not create attestations, or know the --provenance flag
+			"legacy builder",
+			false,
+			[]string{"build", "--quiet", "--platform", "linux/arm64", "-t", repository + ":kettle-build", "app"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := useFakeRunner(t)
+			buildx := fake.Expect("docker", "buildx", "version")
+			if !test.buildx {
+				buildx.ExitCode = 1
+			}
+			fake.Expect("docker", test.build...).Stdout = imageID + "\n"
+			fake.Expect("docker", "tag", repository+":kettle-build", repository+":0123456789ab")
+			fake.Expect("aws", "ecr", "get-login-password").Stdout = "password"
+			fake.Expect("docker", "login", "--username", "AWS", "--password-stdin", "123.dkr.ecr.eu-west-1.amazonaws.com")
+			fake.Expect("docker", "push", repository+":0123456789ab")
+
+			imageURI, id, err := buildAndPushImage("app", repository, "arm64")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imageURI != repository+":0123456789ab" || id != imageID {
+				t.Errorf("buildAndPushImage() = %s, %s; want the image tagged with its ID", imageURI, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+const (
+	// Lambda build images, which match the Lambda runtime's environment
+	// https://gallery.ecr.aws/sam/
+	pythonBuildImage = "public.ecr.aws/sam/build-%s"
+)
+
+// Files that a project's requirements are read from, in order of preference
+var requirementsFiles = []string{
+	"requirements.txt",
+	"pyproject.toml",
+	"Pipfile.lock",
+}
+
+// pythonRequirements are a project's requirements in the requirements.txt format
+type pythonRequirements struct {
+	source   string
+	contents []byte
+}
+
+// buildPythonDependencies installs a project's requirements into a clean directory
+// with pip, and returns the directory. Builds are cached in ~/.kettle/cache by the
+// hash of the requirements (and the files that they include), runtime and architecture
+func buildPythonDependencies(directory string, cfg *config.Config, architecture string, requirements *pythonRequirements) (string, error) {
+	useDocker := cfg.Config.PythonBuild != nil && cfg.Config.PythonBuild.Docker
+
+	cacheDirectory, err := getCacheDirectory("python")
+	if err != nil {
+		return "", err
+	}
+	inputs, cacheable, err := requirements.buildInputs(directory)
+	if err != nil {
+		return "", err
+	}
+	key := getBuildKey(inputs, cfg.Config.Runtime, architecture, fmt.Sprintf("docker=%t", useDocker))
+	packagesDirectory := path.Join(cacheDirectory, key)
+	if _, err := os.Stat(packagesDirectory); err == nil && cacheable {
+		fmt.Printf("🔒  Adding cached dependencies from %s (%s)\n", requirements.source, key[:12])
+		return packagesDirectory, nil
+	}
+
+	// Packages are installed into a temporary directory, which is
+	// only moved into the cache once the build has succeeded
+	buildDirectory, err := ioutil.TempDir(cacheDirectory, "build")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(buildDirectory)
+	if err := os.Mkdir(path.Join(buildDirectory, "packages"), 0755); err != nil {
+		return "", err
+	}
+
+	// A requirements.txt is read in place, so that any paths in it (e.g. -r base.txt)
+	// are relative to the project; other requirements are written to the build directory
+	requirementsFile := requirements.source
+	if requirements.source != "requirements.txt" {
+		requirementsFile = path.Join(buildDirectory, "requirements.txt")
+		if err := ioutil.WriteFile(requirementsFile, requirements.contents, 0644); err != nil {
+			return "", err
+		}
+	}
+
+	fmt.Printf("🔒  Installing dependencies from %s\n", requirements.source)
+	switch {
+	case len(strings.TrimSpace(string(requirements.contents))) == 0:
+		// There is nothing to install (e.g. a pyproject.toml without dependencies)
+	case useDocker:
+		err = installPythonRequirementsWithDocker(directory, buildDirectory, requirementsFile, cfg.Config.Runtime, architecture)
+	default:
+		err = installPythonRequirements(directory, buildDirectory, requirementsFile, cfg.Config.Runtime, architecture)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !cacheable {
+		// The previous build may have different requirements
+		if err := os.RemoveAll(packagesDirectory); err != nil {
+			return "", err
+		}
+	}
+	if err := os.Rename(path.Join(buildDirectory, "packages"), packagesDirectory); err != nil {
+		// Another build of the same requirements may have been moved into the cache first
+		if _, statErr := os.Stat(packagesDirectory); statErr == nil {
+			return packagesDirectory, nil
+		}
+		return "", err
+	}
+	return packagesDirectory, nil
+}
+
+// installPythonRequirements installs the Linux wheels of the requirements, so
+// that the packages match Lambda's platform whichever machine they are built on
+func installPythonRequirements(directory, buildDirectory, requirementsFile, runtime, architecture string) error {
+	if !path.IsAbs(requirementsFile) {
+		requirementsFile = path.Join(directory, requirementsFile)
+	}
+	return cli.Execute("python3", []string{
+		"-m", "pip",
+		"install",
+		"--requirement", requirementsFile,
+		"--target", path.Join(buildDirectory, "packages"),
+		"--platform", fmt.Sprintf("manylinux2014_%s", wheelPlatforms[architecture]),
+		"--implementation", "cp",
+		"--python-version", strings.TrimPrefix(runtime, "python"),
+		"--only-binary=:all:",
+		"--upgrade",
+	}, "Installing Python dependencies")
+}
+
+// installPythonRequirementsWithDocker installs the requirements in a Lambda
+// build image, where packages that only have source distributions can be built
+func installPythonRequirementsWithDocker(directory, buildDirectory, requirementsFile, runtime, architecture string) error {
+	// The project is mounted at /var/task, and the build directory at /kettle
+	if path.IsAbs(requirementsFile) {
+		requirementsFile = path.Join("/kettle", path.Base(requirementsFile))
+	} else {
+		requirementsFile = path.Join("/var/task", requirementsFile)
+	}
+
+	args := []string{
+		"run",
+		"--rm",
+		"--platform", getDockerPlatform(architecture),
+		"-v", fmt.Sprintf("%s:/var/task:ro", directory),
+		"-v", fmt.Sprintf("%s:/kettle", buildDirectory),
+		"-w", "/var/task",
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		// The packages are owned by the current user, instead of root
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	return cli.Execute("docker", append(args,
+		fmt.Sprintf(pythonBuildImage, runtime),
+		"pip",
+		"install",
+		"--requirement", requirementsFile,
+		"--target", "/kettle/packages",
+		"--no-cache-dir",
+	), "Installing Python dependencies with docker")
+}
+
+// requirementIncludePattern matches the lines of a requirements file that include
+// another file: requirements (-r, --requirement) or constraints (-c, --constraint)
+var requirementIncludePattern = regexp.MustCompile(`^(?:-r|-c|--requirement|--constraint)(?:\s*=\s*|\s*)(\S+)`)
+
+// buildInputs returns the requirements and the contents of the files that a
+// requirements.txt includes, which are hashed into the key of a build. Builds
+// that include a URL cannot be cached, since the URL's contents can change
+func (r *pythonRequirements) buildInputs(directory string) ([]byte, bool, error) {
+	inputs := append([]byte{}, r.contents...)
+	if r.source != "requirements.txt" {
+		// Other requirements are written to the build directory, so they cannot include files
+		return inputs, true, nil
+	}
+
+	cacheable := true
+	included := map[string]bool{}
+	var addIncludes func(fileName string, contents []byte) error
+	addIncludes = func(fileName string, contents []byte) error {
+		for _, line := range strings.Split(string(contents), "\n") {
+			match := requirementIncludePattern.FindStringSubmatch(strings.TrimSpace(line))
+			if match == nil {
+				continue
+			}
+			if strings.Contains(match[1], "://") {
+				cacheable = false
+				continue
+			}
+
+			// Paths are relative to the file that includes them
+			includePath := match[1]
+			if !path.IsAbs(includePath) {
+				includePath = path.Join(path.Dir(fileName), includePath)
+			}
+			if included[includePath] {
+				continue
+			}
+			included[includePath] = true
+
+			readPath := includePath
+			if !path.IsAbs(readPath) {
+				readPath = path.Join(directory, includePath)
+			}
+			includeContents, err := os.ReadFile(readPath)
+			if err != nil {
+				return cli.NewConfigError(fmt.Errorf("%s: %w", fileName, err))
+			}
+			inputs = append(inputs, []byte(fmt.Sprintf("\n# %s\n", includePath))...)
+			inputs = append(inputs, includeContents...)
+			if err := addIncludes(includePath, includeContents); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := addIncludes(r.source, r.contents); err != nil {
+		return nil, false, err
+	}
+	return inputs, cacheable, nil
+}
+
+// readPythonRequirements reads a project's requirements from the first
+// requirements file that it has, in the requirements.txt format
+func readPythonRequirements(directory string) (*pythonRequirements, error) {
+	for _, fileName := range requirementsFiles {
+		requirements, err := readRequirementsFile(directory, fileName)
+		if err != nil {
+			return nil, err
+		}
+		if requirements != nil {
+			return requirements, nil
+		}
+	}
+	return nil, cli.NewConfigError(fmt.Errorf("pip needs a %s to install the dependencies",
+		strings.Join(requirementsFiles, ", "),
+	))
+}
+
+// readRequirementsFile reads the requirements in one of the requirementsFiles,
+// or returns nil if the project does not have the file
+func readRequirementsFile(directory, fileName string) (*pythonRequirements, error) {
+	contents, err := os.ReadFile(path.Join(directory, fileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	switch fileName {
+	case "pyproject.toml":
+		contents, err = getPyprojectRequirements(contents)
+	case "Pipfile.lock":
+		contents, err = getPipfileLockRequirements(contents)
+	}
+	if err != nil {
+		return nil, cli.NewConfigError(fmt.Errorf("%s: %w", fileName, err))
+	}
+	return &pythonRequirements{
+		source:   fileName,
+		contents: contents,
+	}, nil
+}
+
+var quotedString = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"|'([^']*)'`)
+
+// getPyprojectRequirements returns the dependencies in the [project] table of a pyproject.toml
+// https://packaging.python.org/en/latest/specifications/declaring-project-metadata/
+func getPyprojectRequirements(contents []byte) ([]byte, error) {
+	table := ""
+	hasProject := false
+	dependencies := ""
+	depth := 0
+	for _, line := range strings.Split(string(contents), "\n") {
+		line, brackets := scanTOMLLine(line)
+		line = strings.TrimSpace(line)
+		if depth > 0 {
+			// A multi-line array continues until its brackets are closed
+			dependencies += line
+			depth += brackets
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			hasProject = hasProject || table == "project"
+			continue
+		}
+		keyValue := strings.SplitN(line, "=", 2)
+		if table != "project" || len(keyValue) != 2 || strings.Trim(keyValue[0], " \"'") != "dependencies" {
+			continue
+		}
+		dependencies = strings.TrimSpace(keyValue[1])
+		depth = brackets
+	}
+	if !hasProject {
+		return nil, fmt.Errorf("no [project] table")
+	}
+
+	requirements := []string{}
+	for _, match := range quotedString.FindAllStringSubmatch(dependencies, -1) {
+		requirements = append(requirements, strings.ReplaceAll(match[1], `\"`, `"`)+match[2])
+	}
+	if len(requirements) == 0 {
+		return []byte{}, nil
+	}
+	return []byte(strings.Join(requirements, "\n") + "\n"), nil
+}
+
+// scanTOMLLine removes a comment from a line of TOML, and returns the
+// line and the change in the depth of the brackets that are not in strings
+func scanTOMLLine(line string) (string, int) {
+	depth := 0
+	var quote rune
+	escaped := false
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			// Only basic (double-quoted) strings have escapes
+			if c == quote && !escaped {
+				quote = 0
+			}
+			escaped = quote == '"' && c == '\\' && !escaped
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i], depth
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		}
+	}
+	return line, depth
+}
+
+type pipfileLockPackage struct {
+	Version string   `json:"version"`
+	Extras  []string `json:"extras"`
+	Markers string   `json:"markers"`
+}
+
+// getPipfileLockRequirements returns the pinned (non-dev) packages in a Pipfile.lock
+func getPipfileLockRequirements(contents []byte) ([]byte, error) {
+	lock := struct {
+		Default map[string]*pipfileLockPackage `json:"default"`
+	}{}
+	if err := json.Unmarshal(contents, &lock); err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range lock.Default {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	requirements := []string{}
+	for _, name := range names {
+		pkg := lock.Default[name]
+		requirement := name
+		if len(pkg.Extras) > 0 {
+			requirement += fmt.Sprintf("[%s]", strings.Join(pkg.Extras, ","))
+		}
+		requirement += pkg.Version
+		if pkg.Markers != "" {
+			requirement += "; " + pkg.Markers
+		}
+		requirements = append(requirements, requirement)
+	}
+	return []byte(strings.Join(requirements, "\n") + "\n"), nil
+}
+
+// getBuildKey returns a hash of the inputs to a build
+func getBuildKey(contents []byte, values ...string) string {
+	h := sha256.New()
+	h.Write(contents)
+	for _, value := range values {
+		h.Write([]byte("\n" + value))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// getCacheDirectory returns (and creates) a directory in ~/.kettle/cache
+func getCacheDirectory(name string) (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	cacheDirectory := path.Join(home, ".kettle", "cache", name)
+	if err := os.MkdirAll(cacheDirectory, 0755); err != nil {
+		return "", err
+	}
+	return cacheDirectory, nil
+}
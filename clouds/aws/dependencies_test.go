@@ -0,0 +1,259 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/operatorai/kettle-cli/cli"
+)
+
+func TestGetPyprojectRequirements(t *testing.T) {
+	tests := []struct {
+		name      string
+		pyproject string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "inline array",
+			pyproject: `[project]
+name = "hello"
+dependencies = ["requests>=2.0", 'boto3']
+`,
+			want: "requests>=2.0\nboto3\n",
+		},
+		{
+			name: "multi-line array with comments",
+			pyproject: `[project]
+name = "hello"
+# dependencies = ["commented-out"]
+dependencies = [ # runtime dependencies
+    "requests[socks]>=2.0",  # "not a dependency"
+    "pywin32; sys_platform == 'win32'",
+]
+`,
+			want: "requests[socks]>=2.0\npywin32; sys_platform == 'win32'\n",
+		},
+		{
+			name: "other tables",
+			pyproject: `[build-system]
+requires = ["setuptools"]
+
+[project]
+dependencies = ["numpy"]
+
+[project.optional-dependencies]
+dev = ["pytest"]
+
+[tool.other]
+dependencies = ["not-this"]
+`,
+			want: "numpy\n",
+		},
+		{
+			name: "escaped quote",
+			pyproject: `[project]
+dependencies = ["a\"b"]
+`,
+			want: "a\"b\n",
+		},
+		{
+			name: "no dependencies",
+			pyproject: `[project]
+name = "hello"
+`,
+			want: "",
+		},
+		{
+			name: "empty dependencies",
+			pyproject: `[project]
+dependencies = []
+`,
+			want: "",
+		},
+		{
+			name: "no project table",
+			pyproject: `[tool.poetry]
+name = "hello"
+`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getPyprojectRequirements([]byte(test.pyproject))
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("getPyprojectRequirements() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("getPyprojectRequirements() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetPipfileLockRequirements(t *testing.T) {
+	lock := `{
+	"_meta": {},
+	"default": {
+		"requests": {"version": "==2.31.0", "extras": ["socks"]},
+		"colorama": {"version": "==0.4.6", "markers": "sys_platform == 'win32'"}
+	},
+	"develop": {
+		"pytest": {"version": "==8.0.0"}
+	}
+}`
+	got, err := getPipfileLockRequirements([]byte(lock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "colorama==0.4.6; sys_platform == 'win32'\nrequests[socks]==2.31.0\n"
+	if string(got) != want {
+		t.Errorf("getPipfileLockRequirements() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildInputs(t *testing.T) {
+	directory := writeProject(t, map[string]string{
+		"requirements.txt":        "-r requirements/base.txt\n--constraint=constraints.txt\nrequests\n",
+		"requirements/base.txt":   "-r common.txt\nnumpy\n",
+		"requirements/common.txt": "-r base.txt\npyyaml\n",
+		"constraints.txt":         "numpy<2\n",
+	})
+	requirements, err := readRequirementsFile(directory, "requirements.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, cacheable, err := requirements.buildInputs(directory)
+	if err != nil || !cacheable {
+		t.Fatalf("buildInputs() = %t, %v; want a cacheable build", cacheable, err)
+	}
+
+	// Each included file (relative to the file that includes it) changes the key
+	for _, fileName := range []string{"requirements/common.txt", "constraints.txt"} {
+		if err := os.WriteFile(filepath.Join(directory, fileName), []byte("changed\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		after, _, err := requirements.buildInputs(directory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(after) == string(before) {
+			t.Errorf("buildInputs() did not change with %s", fileName)
+		}
+		before = after
+	}
+
+	requirements.contents = []byte("-r https://example.com/requirements.txt\n")
+	if _, cacheable, err := requirements.buildInputs(directory); err != nil || cacheable {
+		t.Errorf("buildInputs() with a URL = %t, %v; want an uncacheable build", cacheable, err)
+	}
+
+	requirements.contents = []byte("-r missing.txt\n")
+	if _, _, err := requirements.buildInputs(directory); err == nil {
+		t.Error("buildInputs() with a missing file did not return an error")
+	}
+}
+
+// funcRunner runs a function instead of a command
+type funcRunner func(cmd *cli.Command) ([]byte, error)
+
+func (f funcRunner) Run(cmd *cli.Command) ([]byte, error) {
+	return f(cmd)
+}
+
+// useTestHome points ~ at a temporary directory until the test ends
+func useTestHome(t *testing.T) string {
+	home := t.TempDir()
+	setTestEnv(t, "HOME", home)
+	homedir.DisableCache = true
+	t.Cleanup(func() {
+		homedir.DisableCache = false
+		homedir.Reset()
+	})
+	return home
+}
+
+func TestBuildPythonDependenciesCache(t *testing.T) {
+	useTestHome(t)
+	directory := writeProject(t, map[string]string{"requirements.txt": "requests\n"})
+	cfg := newRuntimeConfig("python3.12")
+	requirements, err := readRequirementsFile(directory, "requirements.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installs := 0
+	previous := cli.GetRunner()
+	defer cli.SetRunner(previous)
+	cli.SetRunner(funcRunner(func(cmd *cli.Command) ([]byte, error) {
+		installs++
+		return nil, nil
+	}))
+
+	first, err := buildPythonDependencies(directory, cfg, "x86_64", requirements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := buildPythonDependencies(directory, cfg, "x86_64", requirements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || installs != 1 {
+		t.Errorf("second build = %s after %d installs, want the cached %s", second, installs, first)
+	}
+
+	if _, err := buildPythonDependencies(directory, cfg, "arm64", requirements); err != nil {
+		t.Fatal(err)
+	}
+	if installs != 2 {
+		t.Errorf("installs = %d, want a new build for arm64", installs)
+	}
+}
+
+func TestBuildPythonDependenciesConcurrentBuild(t *testing.T) {
+	useTestHome(t)
+	directory := writeProject(t, map[string]string{"requirements.txt": "requests\n"})
+	cfg := newRuntimeConfig("python3.12")
+	requirements, err := readRequirementsFile(directory, "requirements.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another build of the same requirements finishes while this one is installing
+	previous := cli.GetRunner()
+	defer cli.SetRunner(previous)
+	cli.SetRunner(funcRunner(func(cmd *cli.Command) ([]byte, error) {
+		inputs, _, err := requirements.buildInputs(directory)
+		if err != nil {
+			return nil, err
+		}
+		cacheDirectory, err := getCacheDirectory("python")
+		if err != nil {
+			return nil, err
+		}
+		key := getBuildKey(inputs, cfg.Config.Runtime, "x86_64", "docker=false")
+		other := filepath.Join(cacheDirectory, key)
+		if err := os.MkdirAll(other, 0755); err != nil {
+			return nil, err
+		}
+		return nil, os.WriteFile(filepath.Join(other, "requests.py"), nil, 0644)
+	}))
+
+	packages, err := buildPythonDependencies(directory, cfg, "x86_64", requirements)
+	if err != nil {
+		t.Fatalf("buildPythonDependencies() = %v, want the other build", err)
+	}
+	if _, err := os.Stat(filepath.Join(packages, "requests.py")); err != nil {
+		t.Errorf("packages = %s, want the other build's directory", packages)
+	}
+}
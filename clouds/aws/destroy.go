@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds/aws/apigateway"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+func (AWSLambdaFunction) Destroy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
+	stg, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	target := newLambdaTarget(cfg, env)
+	fmt.Printf("🧨  Destroying: %s (AWS Lambda function)\n", target.name)
+
+	// Remove the function's resource from the REST API
+	if err := removeLambdaFromRestAPI(target, stg); err != nil {
+		return err
+	}
+
+	exists, err := lambdaFunctionExists(target.name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		// Remove the permissions that allow the API to invoke the function
+		if err := removeInvocationPermission(target); err != nil {
+			return err
+		}
+
+		err := cli.Execute("aws", []string{
+			"lambda",
+			"delete-function",
+			"--function-name", target.name,
+		}, "Deleting lambda function")
+		if err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("⏭  Lambda function %s does not exist\n", target.name)
+	}
+
+	// Remove the function's deployment archives; the bucket is kept, as it is shared by all functions
+	if err := removeAllArtifacts(target, stg.AWS); err != nil {
+		return err
+	}
+	// The state of an environment's function is removed from kettle.json
+	target.deployment.CodeSha256 = ""
+	target.deployment.ImageURI = ""
+	if env != "" {
+		delete(cfg.Config.AWS.Environments, env)
+	}
+
+	return deleteExecutionRole(stg)
+}
+
+func removeLambdaFromRestAPI(target *lambdaTarget, stg *settings.Settings) error {
+	if target.deployment.RestApiResourceID == "" || stg.AWS.RestApiID == "" {
+		return nil
+	}
+
+	err := cli.Execute("aws", []string{
+		"apigateway",
+		"delete-resource",
+		"--rest-api-id", stg.AWS.RestApiID,
+		"--resource-id", target.deployment.RestApiResourceID,
+	}, fmt.Sprintf("Deleting /%s API resource", target.name))
+	if err != nil && !cli.IsExitCode(err, 254) {
+		return err
+	}
+	target.deployment.RestApiResourceID = ""
+
+	// Re-deploy the API so that the resource is removed from the stage
+	return apigateway.Deploy(stg, target.stage)
+}
+
+func removeInvocationPermission(target *lambdaTarget) error {
+	for _, permission := range getInvocationPermissions(target) {
+		statementID := permission[0]
+
+		// Permissions are on the alias, or on the function if it
+		// was added to the REST API before it had an alias
+		for _, qualifier := range []string{liveAlias, ""} {
+			args := []string{
+				"lambda",
+				"remove-permission",
+				"--function-name", target.name,
+				"--statement-id", statementID,
+			}
+			if qualifier != "" {
+				args = append(args, "--qualifier", qualifier)
+			}
+			err := cli.Execute("aws", args, fmt.Sprintf("Removing lambda permissions for: %s", statementID))
+			if err != nil && !cli.IsExitCode(err, 254) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func deleteExecutionRole(stg *settings.Settings) error {
+	// Only the role that kettle creates is deleted; other roles
+	// were selected by the user and may be used elsewhere
+	if !strings.HasSuffix(stg.AWS.RoleArn, fmt.Sprintf("/%s", operatorExecutionRole)) {
+		return nil
+	}
+
+	deleteRole, err := cli.PromptToConfirm("aws.delete_role",
+		fmt.Sprintf("Delete the %s IAM role (it may be used by other functions)", operatorExecutionRole))
+	if err != nil {
+		return err
+	}
+	if !deleteRole {
+		return nil
+	}
+
+	// Roles can only be deleted once all of their policies are detached
+	policies, err := getAttachedPolicies(operatorExecutionRole)
+	if err != nil {
+		return err
+	}
+	for _, policyArn := range policies {
+		err := cli.Execute("aws", []string{
+			"iam",
+			"detach-role-policy",
+			"--role-name", operatorExecutionRole,
+			"--policy-arn", policyArn,
+		}, fmt.Sprintf("Detaching %s from the IAM role", policyArn))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = cli.Execute("aws", []string{
+		"iam",
+		"delete-role",
+		"--role-name", operatorExecutionRole,
+	}, fmt.Sprintf("Deleting the %s IAM role", operatorExecutionRole))
+	if err != nil {
+		return err
+	}
+
+	stg.AWS.RoleArn = ""
+	return nil
+}
@@ -0,0 +1,187 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+const (
+	// The API Gateway stage for deployments without an environment
+	defaultStageName = "prod"
+)
+
+// lambdaTarget is a project's Lambda function in an environment
+type lambdaTarget struct {
+	env        string
+	name       string
+	stage      string
+	deployment *config.AWSDeployment
+}
+
+// newLambdaTarget returns the function for an environment; deployments without
+// an environment use the project name and the prod stage. Those with an
+// environment are named <project>-<env> and use a stage named after the env
+func newLambdaTarget(cfg *config.Config, env string) *lambdaTarget {
+	target := &lambdaTarget{
+		env:        env,
+		name:       cfg.ProjectName,
+		stage:      defaultStageName,
+		deployment: cfg.AWSDeployment(env),
+	}
+	if env != "" {
+		target.name = fmt.Sprintf("%s-%s", cfg.ProjectName, env)
+		target.stage = env
+	}
+	return target
+}
+
+// newDeploymentTarget returns the function for an environment that is being
+// deployed, whose state is stored in kettle.json once the deployment is done
+func newDeploymentTarget(cfg *config.Config, env string) *lambdaTarget {
+	target := newLambdaTarget(cfg, env)
+	target.deployment = cfg.EnsureAWSDeployment(env)
+	return target
+}
+
+// useEnvironment returns the settings to use for an environment. Environments
+// with their own profile or region use their own settings, and the aws cli
+// is pointed at that profile & region until the returned function is called
+func useEnvironment(stg *settings.Settings, env string) (*settings.Settings, func(), error) {
+	if env == "" {
+		return stg, func() {}, nil
+	}
+
+	envSettings, ok := stg.AWS.Environments[env]
+	if !ok {
+		configured := strings.Join(stg.AWS.EnvironmentNames(), ", ")
+		if configured == "" {
+			configured = "none"
+		}
+		return nil, nil, cli.NewUserInputError("unknown environment: %s (configured: %s); run kettle init to add it", env, configured)
+	}
+	if envSettings == nil || (envSettings.Profile == "" && envSettings.DeploymentRegion == "") {
+		return stg, func() {}, nil
+	}
+
+	// An environment with its own profile uses the default region, unless it has its own
+	if envSettings.DeploymentRegion == "" {
+		envSettings.DeploymentRegion = stg.AWS.DeploymentRegion
+	}
+	restore := setProfile(envSettings)
+	if err := SetAccountID(envSettings, false); err != nil {
+		restore()
+		return nil, nil, err
+	}
+
+	return &settings.Settings{
+		GoogleCloud: stg.GoogleCloud,
+		AWS:         envSettings,
+	}, restore, nil
+}
+
+// setProfile sets the environment variables that the aws cli uses to select
+// a profile & region, and returns a function that restores the previous ones
+func setProfile(stg *settings.AWSSettings) func() {
+	previous := map[string]*string{}
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if value, ok := os.LookupEnv(name); ok {
+			previous[name] = &value
+		} else {
+			previous[name] = nil
+		}
+	}
+
+	setProfileEnv("AWS_PROFILE", stg.Profile)
+	setProfileEnv("AWS_REGION", stg.DeploymentRegion)
+	setProfileEnv("AWS_DEFAULT_REGION", stg.DeploymentRegion)
+
+	return func() {
+		for name, value := range previous {
+			if value == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *value)
+			}
+		}
+	}
+}
+
+func setProfileEnv(name, value string) {
+	if value != "" {
+		os.Setenv(name, value)
+	}
+}
+
+// SetEnvironments prompts for the environments that functions can be
+// deployed to, and whether each one uses a separate AWS profile
+func SetEnvironments(stg *settings.AWSSettings, overwrite bool) error {
+	if !overwrite {
+		return nil
+	}
+
+	value, err := cli.PromptForStringWithDefault("aws.environments",
+		"Environments (comma-separated, e.g. dev,staging,prod)",
+		strings.Join(stg.EnvironmentNames(), ","),
+	)
+	if err != nil {
+		return err
+	}
+	names, err := settings.ParseEnvironmentNames(value)
+	if err != nil {
+		return &cli.UserInputError{Err: err}
+	}
+
+	environments := map[string]*settings.AWSSettings{}
+	for _, name := range names {
+		existing, ok := stg.Environments[name]
+		if !ok || existing == nil {
+			existing = &settings.AWSSettings{}
+		}
+
+		separate, err := cli.PromptToConfirm(fmt.Sprintf("aws.%s.separate_profile", name),
+			fmt.Sprintf("Use a separate AWS profile (account) or region for \"%s\"", name))
+		if err != nil {
+			return err
+		}
+		if !separate {
+			environments[name] = &settings.AWSSettings{}
+			continue
+		}
+
+		profile, err := cli.PromptForStringWithDefault(fmt.Sprintf("aws.%s.profile", name),
+			fmt.Sprintf("AWS profile for \"%s\"", name), existing.Profile)
+		if err != nil {
+			return err
+		}
+		if profile != existing.Profile {
+			// A different profile may be a different account
+			existing = &settings.AWSSettings{}
+		}
+		existing.Profile = profile
+
+		if err := setEnvironmentAccount(existing, name); err != nil {
+			return err
+		}
+		environments[name] = existing
+	}
+
+	stg.Environments = environments
+	return nil
+}
+
+// setEnvironmentAccount prompts for the account & region of an environment
+// that uses a separate profile
+func setEnvironmentAccount(stg *settings.AWSSettings, env string) error {
+	restore := setProfile(&settings.AWSSettings{Profile: stg.Profile})
+	defer restore()
+
+	if err := SetAccountID(stg, true); err != nil {
+		return err
+	}
+	return setDeploymentRegion(stg, true, fmt.Sprintf("aws.%s.region", env))
+}
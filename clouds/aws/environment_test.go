@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// useFakeRunner swaps the command runner for a fake until the test ends
+func useFakeRunner(t *testing.T) *cli.FakeRunner {
+	fake := cli.NewFakeRunner()
+	previous := cli.GetRunner()
+	cli.SetRunner(fake)
+	t.Cleanup(func() {
+		cli.SetRunner(previous)
+		if remaining := fake.Remaining(); len(remaining) != 0 {
+			t.Errorf("%d expected commands were not run", len(remaining))
+		}
+	})
+	return fake
+}
+
+// setTestEnv sets an environment variable until the test ends
+func setTestEnv(t *testing.T, name, value string) {
+	previous, ok := os.LookupEnv(name)
+	os.Setenv(name, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestNewLambdaTarget(t *testing.T) {
+	cfg := &config.Config{ProjectName: "hello"}
+	tests := []struct {
+		env       string
+		wantName  string
+		wantStage string
+	}{
+		{"", "hello", "prod"},
+		{"dev", "hello-dev", "dev"},
+		{"prod", "hello-prod", "prod"},
+	}
+	for _, test := range tests {
+		target := newLambdaTarget(cfg, test.env)
+		if target.name != test.wantName || target.stage != test.wantStage {
+			t.Errorf("newLambdaTarget(%q) = %s (stage %s), want %s (stage %s)",
+				test.env, target.name, target.stage, test.wantName, test.wantStage)
+		}
+	}
+
+	// Looking up a target does not add its environment to the config
+	if len(cfg.Config.AWS.Environments) != 0 {
+		t.Errorf("environments = %v, want none", cfg.Config.AWS.Environments)
+	}
+	newDeploymentTarget(cfg, "dev").deployment.CodeSha256 = "abc"
+	if got := newLambdaTarget(cfg, "dev").deployment.CodeSha256; got != "abc" {
+		t.Errorf("CodeSha256 = %q, want abc", got)
+	}
+}
+
+func TestUseEnvironment(t *testing.T) {
+	stg := &settings.Settings{
+		AWS: &settings.AWSSettings{
+			AccountID:        "111111111111",
+			DeploymentRegion: "eu-west-1",
+			Environments: map[string]*settings.AWSSettings{
+				"dev":  {},
+				"prod": {Profile: "production"},
+			},
+		},
+	}
+
+	// Environments without their own profile use the default settings
+	for _, env := range []string{"", "dev"} {
+		got, restore, err := useEnvironment(stg, env)
+		if err != nil {
+			t.Fatalf("useEnvironment(%q): %v", env, err)
+		}
+		restore()
+		if got != stg {
+			t.Errorf("useEnvironment(%q) did not return the default settings", env)
+		}
+	}
+
+	_, _, err := useEnvironment(stg, "staging")
+	var inputErr *cli.UserInputError
+	if !errors.As(err, &inputErr) {
+		t.Errorf("useEnvironment(staging): err = %v, want a UserInputError", err)
+	}
+}
+
+func TestUseEnvironmentWithProfile(t *testing.T) {
+	setTestEnv(t, "AWS_PROFILE", "default")
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		setTestEnv(t, name, "")
+		os.Unsetenv(name)
+	}
+
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "sts", "get-caller-identity", "--output", "json").Stdout = `{"Account": "222222222222"}`
+
+	prod := &settings.AWSSettings{Profile: "production"}
+	stg := &settings.Settings{
+		AWS: &settings.AWSSettings{
+			AccountID:        "111111111111",
+			DeploymentRegion: "eu-west-1",
+			Environments:     map[string]*settings.AWSSettings{"prod": prod},
+		},
+	}
+
+	got, restore, err := useEnvironment(stg, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AWS != prod || prod.AccountID != "222222222222" || prod.DeploymentRegion != "eu-west-1" {
+		t.Errorf("useEnvironment(prod) = %+v, want the prod settings in eu-west-1", got.AWS)
+	}
+	if os.Getenv("AWS_PROFILE") != "production" || os.Getenv("AWS_REGION") != "eu-west-1" {
+		t.Errorf("AWS_PROFILE = %q, AWS_REGION = %q while the environment is in use",
+			os.Getenv("AWS_PROFILE"), os.Getenv("AWS_REGION"))
+	}
+
+	restore()
+	if os.Getenv("AWS_PROFILE") != "default" {
+		t.Errorf("AWS_PROFILE = %q after restoring, want default", os.Getenv("AWS_PROFILE"))
+	}
+	if _, ok := os.LookupEnv("AWS_REGION"); ok {
+		t.Errorf("AWS_REGION is set after restoring")
+	}
+}
@@ -33,7 +33,7 @@ func setExecutionRole(stg *settings.Settings, overwrite bool) error {
 			return err
 		}
 	} else {
-		role, err = cli.PromptForValue("IAM Role", roles, !operatorExecutionRoleExists)
+		role, err = cli.PromptForValue("aws.role", "IAM Role", roles, !operatorExecutionRoleExists)
 		if err != nil {
 			return err
 		}
@@ -136,3 +136,30 @@ func createExecutionRole() (string, error) {
 	}
 	return result.Role.Arn, nil
 }
+
+func getAttachedPolicies(roleName string) ([]string, error) {
+	output, err := cli.ExecuteWithResult("aws", []string{
+		"iam",
+		"list-attached-role-policies",
+		"--role-name", roleName,
+		"--output", "json",
+	}, "Collecting the IAM role's policies")
+	if err != nil {
+		return nil, err
+	}
+
+	var results struct {
+		AttachedPolicies []struct {
+			PolicyArn string `json:"PolicyArn"`
+		} `json:"AttachedPolicies"`
+	}
+	if err := json.Unmarshal(output, &results); err != nil {
+		return nil, err
+	}
+
+	policies := []string{}
+	for _, policy := range results.AttachedPolicies {
+		policies = append(policies, policy.PolicyArn)
+	}
+	return policies, nil
+}
@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/invoke"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Endpoint returns the function's REST API endpoint, or nil if
+// the function has not been added to a REST API
+func (AWSLambdaFunction) Endpoint(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *invoke.Options) (*invoke.Endpoint, error) {
+	if opts.IdentityToken {
+		return nil, cli.NewUserInputError("identity tokens are only supported for Google Cloud")
+	}
+	stg, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	target := newLambdaTarget(cfg, env)
+	if stg.AWS.RestApiID == "" || target.deployment.RestApiResourceID == "" {
+		return nil, nil
+	}
+
+	endpoint := &invoke.Endpoint{
+		URL:     getEndpointURL(target, stg),
+		Headers: map[string]string{},
+	}
+	if opts.APIKey != "" {
+		endpoint.Headers["x-api-key"] = opts.APIKey
+	}
+	return endpoint, nil
+}
+
+// Invoke calls the function directly with aws lambda invoke
+// https://awscli.amazonaws.com/v2/documentation/api/latest/reference/lambda/invoke.html
+func (AWSLambdaFunction) Invoke(directory string, cfg *config.Config, stg *settings.Settings, env string, payload []byte) (*invoke.Response, error) {
+	_, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+	target := newLambdaTarget(cfg, env)
+
+	// The payload and the response are passed in files
+	payloadFile, err := ioutil.TempFile("", "kettle-payload*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(payloadFile.Name())
+	defer payloadFile.Close()
+	if _, err := payloadFile.Write(payload); err != nil {
+		return nil, err
+	}
+
+	outputFile, err := ioutil.TempFile("", "kettle-response*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(outputFile.Name())
+	outputFile.Close()
+
+	// Invoke the version that the REST API invokes, if the function has an alias
+	args := []string{
+		"lambda",
+		"invoke",
+		"--function-name", target.name,
+		"--payload", fmt.Sprintf("fileb://%s", payloadFile.Name()),
+		"--output", "json",
+	}
+	alias, err := getAlias(target)
+	if err != nil {
+		return nil, err
+	}
+	if alias != nil {
+		args = append(args, "--qualifier", liveAlias)
+	}
+
+	start := time.Now()
+	output, err := cli.ExecuteWithResult("aws", append(args, outputFile.Name()), "Invoking lambda function")
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+
+	var result struct {
+		StatusCode      int    `json:"StatusCode"`
+		FunctionError   string `json:"FunctionError"`
+		ExecutedVersion string `json:"ExecutedVersion"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadFile(outputFile.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &invoke.Response{
+		StatusCode:    result.StatusCode,
+		Headers:       map[string]string{},
+		Body:          body,
+		Latency:       latency,
+		FunctionError: result.FunctionError,
+	}
+	if result.ExecutedVersion != "" {
+		response.Headers["Executed-Version"] = result.ExecutedVersion
+	}
+	return response, nil
+}
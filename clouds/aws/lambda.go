@@ -1,32 +1,53 @@
 package aws
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/clouds/aws/apigateway"
 	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/ignore"
 	"github.com/operatorai/kettle-cli/settings"
 )
 
 type AWSLambdaFunction struct{}
 
-func (AWSLambdaFunction) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
-	if env != "" {
-		fmt.Printf("🚨  Environments for AWS Lambda functions are unimplemented (%s)\n", env)
+func (AWSLambdaFunction) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) (*config.Deployment, error) {
+	stg, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return nil, err
 	}
+	defer restore()
 
-	fmt.Printf("🚢  Deploying: %s as an AWS Lambda function\n", cfg.ProjectName)
+	target := newDeploymentTarget(cfg, env)
+	fmt.Printf("🚢  Deploying: %s as an AWS Lambda function\n", target.name)
 	fmt.Printf("⏭  Entry point: %s (%s)\n", cfg.Config.EntryFunction, cfg.Config.Runtime)
-	// @TODO future - container-based deployments
-	deploymentArchive, err := createDeploymentArchive(cfg)
+
+	// The code is built for the function's instruction set
+	function, err := getLambdaFunction(target.name)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	architecture, err := getLambdaArchitecture(cfg, function)
+	if err != nil {
+		return nil, err
+	}
+
+	// The source is hashed before it is built (e.g. into a Go binary)
+	packageHash, err := getPackageHash(directory)
+	if err != nil {
+		return nil, err
+	}
+	deploymentArchive, err := createDeploymentArchive(directory, cfg, architecture)
+	if err != nil {
+		return nil, err
 	}
 	defer func() {
 		// Clean up deployment package (ignore errors)
-		err := removeDeploymentArchive(cfg)
+		err := removeDeploymentArchive(directory, cfg)
 		if err != nil {
 			if settings.DebugMode {
 				fmt.Println(err.Error())
@@ -34,70 +55,229 @@ func (AWSLambdaFunction) Deploy(directory string, cfg *config.Config, stg *setti
 		}
 	}()
 
-	var waitType string
-	exists, err := lambdaFunctionExists(cfg.ProjectName)
+	codeSha256, err := getCodeSha256(deploymentArchive)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if exists {
-		// Update the function with the new code
-		waitType = "function-updated"
-		if err := updateLambda(deploymentArchive, cfg); err != nil {
-			return err
+
+	variables, err := getFunctionVariables(directory, cfg, env)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := getFunctionResources(cfg, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archives that are too large to upload directly are uploaded to S3
+	var code *lambdaCode
+	changed := true
+	if function != nil {
+		// A function that is moved to a new runtime (e.g. from go1.x) needs new code too
+		runtimeChanged, err := hasRuntimeChanged(function, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		// Update the function with the new code, unless the deployed code is identical;
+		// the architecture can only be changed together with the code
+		architectureChanged := architecture != getFunctionArchitecture(function)
+		if architectureChanged {
+			fmt.Printf("🔀  Updating architecture: %s -> %s\n", getFunctionArchitecture(function), architecture)
+		}
+		codeChanged := function.Configuration.CodeSha256 != codeSha256 || architectureChanged || runtimeChanged || settings.ForceDeploy
+		if codeChanged {
+			code, err = getLambdaCode(deploymentArchive, target, stg, codeSha256)
+			if err != nil {
+				return nil, err
+			}
+			if err := updateLambda(code, target, architecture); err != nil {
+				return nil, err
+			}
+			if err := waitForLambda("function-updated", target); err != nil {
+				return nil, err
+			}
+		} else {
+			fmt.Printf("⏭  No changes to the code (hash: %s); use --force to re-deploy\n", codeSha256)
 		}
+
+		// The runtime & handler are changed once the function has the code for them
+		if runtimeChanged {
+			if err := updateFunctionRuntime(function, target, cfg); err != nil {
+				return nil, err
+			}
+		}
+
+		// Update the function's configuration, if it has changed
+		configurationChanged, err := updateFunctionConfiguration(function, target, variables, resources)
+		if err != nil {
+			return nil, err
+		}
+		changed = runtimeChanged || codeChanged || configurationChanged
 	} else {
 		// Create the Lambda function
-		waitType = "function-active"
-		if err := createLambdaFunction(deploymentArchive, cfg.Config.EntryFunction, cfg, stg); err != nil {
-			return err
+		code, err = getLambdaCode(deploymentArchive, target, stg, codeSha256)
+		if err != nil {
+			return nil, err
+		}
+		if err := createLambdaFunction(code, target, architecture, cfg, stg, variables, resources); err != nil {
+			return nil, err
+		}
+		if err := setReservedConcurrency(&lambdaFunction{}, target, resources); err != nil {
+			return nil, err
 		}
+		if err := waitForLambda("function-active", target); err != nil {
+			return nil, err
+		}
+	}
 
+	target.deployment.CodeSha256 = codeSha256
+	deployment, err := releaseLambda(target, stg, function == nil, changed, codeSha256, packageHash)
+	if err != nil {
+		return nil, err
+	}
+	if code != nil && code.s3Bucket != "" {
+		// The deployment has succeeded, so failing to tidy up the bucket is not an error
+		if err := removeOldArtifacts(target, stg.AWS); err != nil {
+			cli.Warn("Could not remove old deployment archives from %s: %s", code.s3Bucket, err)
+		}
+	}
+	return deployment, nil
+}
+
+// releaseLambda publishes a version of a function that has been deployed, points the
+// alias that the REST API invokes to it, and (for new functions) adds it to a REST API.
+// It returns nil if neither the function nor its alias have changed
+func releaseLambda(target *lambdaTarget, stg *settings.Settings, created, changed bool, codeSha256, packageHash string) (*config.Deployment, error) {
+	// Publish a version, and point the alias that the REST API invokes to it; the alias
+	// is also moved if nothing has changed since a rollback to an earlier version
+	version, err := publishVersion(target, codeSha256)
+	if err != nil {
+		return nil, err
+	}
+	aliasChanged, err := setAlias(target, version.Version, stg)
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
 		// Note: if the first deployment of a function fails after the function has
 		// been created, then there is currently no way to re-deploy and create the
 		// REST API. This should be changed so that a deployment asks whether to add
 		// a function to an API if e.g. it hasn't already been added to one
-		if cli.PromptToConfirm("Add Lambda function to a REST API") {
-			if err := addLambdaToRestAPI(deploymentArchive, cfg, stg); err != nil {
-				return err
+		addToRestAPI, err := cli.PromptToConfirm("aws.add_to_rest_api", "Add Lambda function to a REST API")
+		if err != nil {
+			return nil, err
+		}
+		if addToRestAPI {
+			if err := addLambdaToRestAPI(target, stg); err != nil {
+				return nil, err
 			}
-
-			url := fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/prod/%s",
-				stg.AWS.RestApiID,
-				stg.AWS.DeploymentRegion,
-				cfg.ProjectName,
-			)
-			fmt.Println("🔍  API Endpoint: ", url)
+			fmt.Println("🔍  API Endpoint: ", getEndpointURL(target, stg))
 		}
 	}
-	return waitForLambda(waitType, cfg)
+
+	result := cli.GetResult()
+	result.FunctionArn = strings.TrimSuffix(version.FunctionArn, ":"+version.Version)
+	if stg.AWS.RestApiID != "" && target.deployment.RestApiResourceID != "" {
+		result.EndpointURL = getEndpointURL(target, stg)
+	}
+
+	if !changed && !aliasChanged {
+		return nil, nil
+	}
+	fmt.Printf("🏷  Version: %s (%s alias)\n", version.Version, liveAlias)
+	return &config.Deployment{
+		PackageHash:      packageHash,
+		LambdaVersionArn: version.FunctionArn,
+	}, nil
 }
 
-func lambdaFunctionExists(name string) (bool, error) {
-	_, err := cli.ExecuteWithResult("aws", []string{
+// getPackageHash returns a hash of the project's source (the files that are
+// not in .kettleignore), which is recorded in the deployment history; unlike
+// the CodeSha256, it does not change when the dependencies are reinstalled
+func getPackageHash(directory string) (string, error) {
+	matcher, err := ignore.Load(directory)
+	if err != nil {
+		return "", err
+	}
+	return ignore.Hash(directory, matcher)
+}
+
+func getEndpointURL(target *lambdaTarget, stg *settings.Settings) string {
+	return fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s/%s",
+		stg.AWS.RestApiID,
+		stg.AWS.DeploymentRegion,
+		target.stage,
+		target.name,
+	)
+}
+
+type lambdaFunction struct {
+	Configuration struct {
+		FunctionName  string   `json:"FunctionName"`
+		FunctionArn   string   `json:"FunctionArn"`
+		CodeSha256    string   `json:"CodeSha256"`
+		PackageType   string   `json:"PackageType"`
+		Architectures []string `json:"Architectures"`
+		Runtime       string   `json:"Runtime"`
+		Handler       string   `json:"Handler"`
+		LastModified  string   `json:"LastModified"`
+		MemorySize    int      `json:"MemorySize"`
+		Timeout       int      `json:"Timeout"`
+		Environment   struct {
+			Variables map[string]string `json:"Variables"`
+		} `json:"Environment"`
+	} `json:"Configuration"`
+	Code struct {
+		ImageUri string `json:"ImageUri"`
+	} `json:"Code"`
+	Concurrency struct {
+		ReservedConcurrentExecutions *int `json:"ReservedConcurrentExecutions"`
+	} `json:"Concurrency"`
+}
+
+// getLambdaFunction returns nil if the function does not exist
+func getLambdaFunction(name string) (*lambdaFunction, error) {
+	output, err := cli.ExecuteWithResult("aws", []string{
 		"lambda",
 		"get-function",
 		"--function-name", name,
+		"--output", "json",
 	}, "Checking status of lambda function")
 	if err != nil {
-		if err.Error() == "exit status 254" {
-			return false, nil
+		if cli.IsExitCode(err, 254) {
+			return nil, nil
 		}
+		return nil, err
+	}
+
+	function := &lambdaFunction{}
+	if err := json.Unmarshal(output, function); err != nil {
+		return nil, err
+	}
+	return function, nil
+}
+
+func lambdaFunctionExists(name string) (bool, error) {
+	function, err := getLambdaFunction(name)
+	if err != nil {
 		return false, err
 	}
-	return true, nil
+	return function != nil, nil
 }
 
-func updateLambda(deploymentArchive string, cfg *config.Config) error {
-	return cli.Execute("aws", []string{
+func updateLambda(code *lambdaCode, target *lambdaTarget, architecture string) error {
+	return cli.Execute("aws", append([]string{
 		"lambda",
 		"update-function-code",
-		"--function-name", cfg.ProjectName,
-		"--zip-file", fmt.Sprintf("fileb://%s", deploymentArchive),
-	}, "Updating lambda function code")
+		"--function-name", target.name,
+		"--architectures", architecture,
+	}, code.updateArgs()...), "Updating lambda function code")
 }
 
 // https://docs.aws.amazon.com/lambda/latest/dg/services-apigateway-tutorial.html
-func addLambdaToRestAPI(deploymentArchive string, cfg *config.Config, stg *settings.Settings) error {
+func addLambdaToRestAPI(target *lambdaTarget, stg *settings.Settings) error {
 	// Create or set the REST API
 	if err := apigateway.SetRestApiID(stg, false); err != nil {
 		return err
@@ -115,33 +295,48 @@ func addLambdaToRestAPI(deploymentArchive string, cfg *config.Config, stg *setti
 	}
 
 	// Create a resource in the API & create a POST method on the resource
-	if err := apigateway.SetResourceID(resources, cfg, stg); err != nil {
+	if err := apigateway.SetResourceID(resources, target.name, target.deployment, stg); err != nil {
 		return err
 	}
 
 	// Set the Lambda function as the destination for the POST method
-	if err := addFunctionIntegration(cfg, stg); err != nil {
+	if err := addFunctionIntegration(target, stg); err != nil {
 		return err
 	}
 
 	// Set the response codes across the Lambda & API gateway
-	if err := addIntegrationResponses(cfg, stg); err != nil {
+	if err := addIntegrationResponses(target, stg); err != nil {
 		return err
 	}
 
 	// Deploy the API with the new resource & integration
-	if err := apigateway.Deploy(stg); err != nil {
+	if err := apigateway.Deploy(stg, target.stage); err != nil {
 		return err
 	}
 
 	// Grant invoke permission to the API
-	if err := addInvocationPermission(cfg, stg); err != nil {
+	if err := addInvocationPermission(target, stg); err != nil {
 		return err
 	}
 	return nil
 }
 
-func createLambdaFunction(deploymentArchive string, functionName string, cfg *config.Config, stg *settings.Settings) error {
+func createLambdaFunction(code *lambdaCode, target *lambdaTarget, architecture string, cfg *config.Config, stg *settings.Settings, variables map[string]string, resources *config.Resources) error {
+	handler, runtime, err := getLambdaRuntime(cfg)
+	if err != nil {
+		return err
+	}
+	return createFunction(target, stg, variables, resources, append([]string{
+		"--runtime", runtime,
+		"--handler", handler,
+		"--architectures", architecture,
+		"--package-type", "Zip",
+	}, code.createArgs()...)...)
+}
+
+// createFunction creates a function with its execution role, resources and
+// environment variables; codeArgs set its code (e.g. a zip file or an image)
+func createFunction(target *lambdaTarget, stg *settings.Settings, variables map[string]string, resources *config.Resources, codeArgs ...string) error {
 	// Get the current AWS account ID
 	if err := SetAccountID(stg.AWS, false); err != nil {
 		return err
@@ -152,68 +347,65 @@ func createLambdaFunction(deploymentArchive string, functionName string, cfg *co
 		return err
 	}
 
-	// The --handler option in the create-function command changes based on the
-	// programming language
-	var handler, runtime string
-	switch {
-	case strings.HasPrefix(cfg.Config.Runtime, "python"):
-		handler = fmt.Sprintf("main.%s", functionName)
-		runtime = cfg.Config.Runtime
-	case strings.HasPrefix(cfg.Config.Runtime, "go"):
-		handler = "main"
-		runtime = "go1.x"
-	default:
-		return fmt.Errorf("unknown runtime: %s", cfg.Config.Runtime)
-	}
-
-	// Create the Lambda function
-	return cli.Execute("aws", []string{
+	args := []string{
 		"lambda",
 		"create-function",
-		"--function-name", cfg.ProjectName,
-		"--runtime", runtime,
+		"--function-name", target.name,
 		"--role", stg.AWS.RoleArn,
-		"--handler", handler,
-		"--package-type", "Zip",
-		"--zip-file", fmt.Sprintf("fileb://%s", deploymentArchive),
-	}, "Creating new lambda function")
+	}
+	args = append(args, codeArgs...)
+	args = append(args, resourceArgs(resources)...)
+
+	// Set the function's environment variables
+	if len(variables) > 0 {
+		environmentFile, err := writeEnvironmentFile(variables)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(environmentFile)
+		args = append(args, "--environment", fmt.Sprintf("file://%s", environmentFile))
+	}
+
+	// Create the Lambda function
+	return cli.Execute("aws", args, "Creating new lambda function")
 }
 
-func waitForLambda(waitType string, cfg *config.Config) error {
+func waitForLambda(waitType string, target *lambdaTarget) error {
 	return cli.Execute("aws", []string{
 		"lambda",
 		"wait",
 		waitType,
-		"--function-name", cfg.ProjectName,
+		"--function-name", target.name,
 	}, "Waiting for function to be active")
 }
 
-func addFunctionIntegration(cfg *config.Config, stg *settings.Settings) error {
-	// Create the integration between the API gateway and the Lambda
+func addFunctionIntegration(target *lambdaTarget, stg *settings.Settings) error {
+	// Create the integration between the API gateway and the Lambda's alias
 	return cli.Execute("aws", []string{
 		"apigateway",
 		"put-integration",
 		"--rest-api-id", stg.AWS.RestApiID,
-		"--resource-id", cfg.Config.AWS.RestApiResourceID,
+		"--resource-id", target.deployment.RestApiResourceID,
 		"--http-method", "POST",
 		"--type", "AWS",
 		"--integration-http-method", "POST",
-		"--uri", fmt.Sprintf("arn:aws:apigateway:%s:lambda:path/2015-03-31/functions/arn:aws:lambda:%s:%s:function:%s/invocations",
+		"--uri", fmt.Sprintf("arn:aws:apigateway:%s:lambda:path/2015-03-31/functions/arn:aws:lambda:%s:%s:function:%s:%s/invocations",
 			stg.AWS.DeploymentRegion,
 			stg.AWS.DeploymentRegion,
 			stg.AWS.AccountID,
-			cfg.ProjectName,
+			target.name,
+			liveAlias,
 		),
 	}, "Integrating the lambda function with the API resource")
 }
 
-func addIntegrationResponses(cfg *config.Config, stg *settings.Settings) error {
+func addIntegrationResponses(target *lambdaTarget, stg *settings.Settings) error {
 	// Set any responses matching the ".*error.*" regex to have status 500
 	err := cli.Execute("aws", []string{
 		"apigateway",
 		"put-integration-response",
 		"--rest-api-id", stg.AWS.RestApiID,
-		"--resource-id", cfg.Config.AWS.RestApiResourceID,
+		"--resource-id", target.deployment.RestApiResourceID,
 		"--http-method", "POST",
 		"--status-code", "500",
 		"--selection-pattern", ".*error.*", // .*error.*
@@ -228,7 +420,7 @@ func addIntegrationResponses(cfg *config.Config, stg *settings.Settings) error {
 		"put-method-response",
 		"--region", stg.AWS.DeploymentRegion,
 		"--rest-api-id", stg.AWS.RestApiID,
-		"--resource-id", cfg.Config.AWS.RestApiResourceID,
+		"--resource-id", target.deployment.RestApiResourceID,
 		"--http-method", "POST",
 		"--status-code", "500",
 	}, "Setting the gateway error response")
@@ -241,35 +433,42 @@ func addIntegrationResponses(cfg *config.Config, stg *settings.Settings) error {
 		"apigateway",
 		"put-integration-response",
 		"--rest-api-id", stg.AWS.RestApiID,
-		"--resource-id", cfg.Config.AWS.RestApiResourceID,
+		"--resource-id", target.deployment.RestApiResourceID,
 		"--http-method", "POST",
 		"--status-code", "200",
 		"--response-templates", "application/json=\"\"",
 	}, "Setting the default integration response to JSON")
 }
 
-func addInvocationPermission(cfg *config.Config, stg *settings.Settings) error {
+// getInvocationPermissions returns the statement IDs & stages of the
+// permissions that allow the API to invoke a function
+func getInvocationPermissions(target *lambdaTarget) [][2]string {
 	// The wildcard character (*) as the stage value indicates testing only
-	permissions := map[string]string{
-		"test": "*",
-		"prod": "prod",
+	return [][2]string{
+		{"operator-apigateway-test", "*"},
+		{fmt.Sprintf("operator-apigateway-%s", target.stage), target.stage},
 	}
-	for env, permission := range permissions {
+}
+
+func addInvocationPermission(target *lambdaTarget, stg *settings.Settings) error {
+	for _, permission := range getInvocationPermissions(target) {
+		statementID, stage := permission[0], permission[1]
 		err := cli.Execute("aws", []string{
 			"lambda",
 			"add-permission",
-			"--function-name", cfg.ProjectName,
-			"--statement-id", fmt.Sprintf("operator-apigateway-%s", env),
+			"--function-name", target.name,
+			"--qualifier", liveAlias,
+			"--statement-id", statementID,
 			"--action", "lambda:InvokeFunction",
 			"--principal", "apigateway.amazonaws.com",
 			"--source-arn", fmt.Sprintf("arn:aws:execute-api:%s:%s:%s/%s/POST/%s",
 				stg.AWS.DeploymentRegion,
 				stg.AWS.AccountID,
 				stg.AWS.RestApiID,
-				permission,
-				cfg.ProjectName,
+				stage,
+				target.name,
 			),
-		}, fmt.Sprintf("Setting lambda permissions for: %s", env))
+		}, fmt.Sprintf("Setting lambda permissions for: %s", statementID))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/logs"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Logs reads the function's log group in CloudWatch
+// https://awscli.amazonaws.com/v2/documentation/api/latest/reference/logs/tail.html
+func (AWSLambdaFunction) Logs(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *logs.Options) error {
+	stg, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	target := newLambdaTarget(cfg, env)
+	if !opts.JSON {
+		fmt.Printf("📜  Logs: %s (AWS Lambda function)\n", target.name)
+	}
+
+	args := []string{
+		"logs",
+		"tail",
+		fmt.Sprintf("/aws/lambda/%s", target.name),
+		"--region", stg.AWS.DeploymentRegion,
+		"--since", opts.Since,
+		"--format", "short",
+	}
+	if opts.JSON {
+		args[len(args)-1] = "json"
+	}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter-pattern", opts.Filter)
+	}
+	return cli.Stream("aws", args, "Reading logs")
+}
@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/ignore"
+)
+
+// nodePackageManager installs a Node.js function's dependencies; it is picked
+// by the lock file that is in the project
+type nodePackageManager struct {
+	name     string
+	lockFile string
+}
+
+var nodePackageManagers = []*nodePackageManager{
+	{name: "pnpm", lockFile: "pnpm-lock.yaml"},
+	{name: "yarn", lockFile: "yarn.lock"},
+	{name: "npm", lockFile: "package-lock.json"},
+}
+
+func getNodePackageManager(directory string) *nodePackageManager {
+	for _, manager := range nodePackageManagers {
+		if fileExists(directory, manager.lockFile) {
+			return manager
+		}
+	}
+	// npm install is used for projects without a lock file
+	return &nodePackageManager{name: "npm"}
+}
+
+// run runs a script in package.json (e.g. to compile TypeScript)
+func (m *nodePackageManager) run(directory, script string) error {
+	args := []string{"run", script}
+	switch m.name {
+	case "npm":
+		args = append(args, "--prefix", directory)
+	case "pnpm":
+		args = append(args, "--dir", directory)
+	case "yarn":
+		args = append(args, "--cwd", directory)
+	}
+	return cli.Execute(m.name, args, fmt.Sprintf("Running %s run %s", m.name, script))
+}
+
+// RunNodeScript runs a script in a project's package.json with the package
+// manager of its lock file
+func RunNodeScript(directory, script string) error {
+	return getNodePackageManager(directory).run(directory, script)
+}
+
+// installProduction installs the dependencies in a package.json, without its
+// devDependencies, into the node_modules of a directory
+func (m *nodePackageManager) installProduction(directory string) error {
+	var args []string
+	switch {
+	case m.name == "pnpm":
+		// pnpm links packages by default, and linked directories are not archived
+		args = []string{"install", "--prod", "--frozen-lockfile", "--config.node-linker=hoisted", "--dir", directory}
+	case m.name == "yarn":
+		args = []string{"install", "--production", "--frozen-lockfile", "--cwd", directory}
+	case m.lockFile != "":
+		args = []string{"ci", "--omit=dev", "--prefix", directory}
+	default:
+		args = []string{"install", "--omit=dev", "--prefix", directory}
+	}
+	return cli.Execute(m.name, args, fmt.Sprintf("Installing Node.js dependencies with %s", m.name))
+}
+
+// getNodeHandler returns the handler of a Node.js function: the entry function
+// exported by index.js, which is in the output directory of a build
+func getNodeHandler(cfg *config.Config) string {
+	handler := fmt.Sprintf("index.%s", cfg.Config.EntryFunction)
+	if cfg.Config.NodeBuild != nil && cfg.Config.NodeBuild.OutputDirectory != "" {
+		return path.Join(strings.Trim(cfg.Config.NodeBuild.OutputDirectory, "/"), handler)
+	}
+	return handler
+}
+
+// https://docs.aws.amazon.com/lambda/latest/dg/nodejs-package.html
+func addNodeLambdaToArchive(archive *deploymentArchive, directory string, cfg *config.Config) error {
+	manager := getNodePackageManager(directory)
+	if cfg.Config.NodeBuild != nil && cfg.Config.NodeBuild.BuildScript != "" {
+		if err := manager.run(directory, cfg.Config.NodeBuild.BuildScript); err != nil {
+			return err
+		}
+	}
+
+	// The production dependencies are installed into a clean directory, so
+	// that the project's local node_modules (with devDependencies) are not deployed
+	if fileExists(directory, "package.json") {
+		buildDirectory, err := ioutil.TempDir("", "kettle-node")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(buildDirectory)
+
+		for _, fileName := range []string{"package.json", manager.lockFile, ".npmrc"} {
+			if fileName == "" || !fileExists(directory, fileName) {
+				continue
+			}
+			contents, err := os.ReadFile(path.Join(directory, fileName))
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(path.Join(buildDirectory, fileName), contents, 0644); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("📦  Installing dependencies from package.json with %s\n", manager.name)
+		if err := manager.installProduction(buildDirectory); err != nil {
+			return err
+		}
+		err = archive.addDirectory(buildDirectory, func(relativePath string, isDir bool) bool {
+			return relativePath != "node_modules" && !strings.HasPrefix(relativePath, "node_modules/")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add the contents of the function directory (except for anything in .kettleignore)
+	matcher, err := ignore.Load(directory)
+	if err != nil {
+		return err
+	}
+	return archive.addDirectory(directory, matcher.Match)
+}
@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+func TestGetNodePackageManager(t *testing.T) {
+	tests := []struct {
+		files    map[string]string
+		want     string
+		lockFile string
+	}{
+		{map[string]string{"package.json": "{}", "pnpm-lock.yaml": ""}, "pnpm", "pnpm-lock.yaml"},
+		{map[string]string{"package.json": "{}", "yarn.lock": ""}, "yarn", "yarn.lock"},
+		{map[string]string{"package.json": "{}", "package-lock.json": "{}"}, "npm", "package-lock.json"},
+		{map[string]string{"package.json": "{}"}, "npm", ""},
+	}
+	for _, test := range tests {
+		manager := getNodePackageManager(writeProject(t, test.files))
+		if manager.name != test.want || manager.lockFile != test.lockFile {
+			t.Errorf("getNodePackageManager(%v) = %s (%q), want %s (%q)",
+				test.files, manager.name, manager.lockFile, test.want, test.lockFile)
+		}
+	}
+}
+
+func TestGetNodeHandler(t *testing.T) {
+	tests := []struct {
+		nodeBuild *config.NodeBuild
+		want      string
+	}{
+		{nil, "index.handler"},
+		{&config.NodeBuild{BuildScript: "build"}, "index.handler"},
+		{&config.NodeBuild{BuildScript: "build", OutputDirectory: "dist/"}, "dist/index.handler"},
+		{&config.NodeBuild{OutputDirectory: "/build/lambda/"}, "build/lambda/index.handler"},
+	}
+	for _, test := range tests {
+		cfg := newRuntimeConfig("nodejs20.x")
+		cfg.Config.NodeBuild = test.nodeBuild
+		if got := getNodeHandler(cfg); got != test.want {
+			t.Errorf("getNodeHandler(%+v) = %s, want %s", test.nodeBuild, got, test.want)
+		}
+	}
+}
+
+func TestNodePackageManagerArgs(t *testing.T) {
+	tests := []struct {
+		manager *nodePackageManager
+		install []string
+		run     []string
+	}{
+		{
+			&nodePackageManager{name: "npm", lockFile: "package-lock.json"},
+			[]string{"ci", "--omit=dev", "--prefix", "build"},
+			[]string{"run", "build", "--prefix", "app"},
+		},
+		{
+			&nodePackageManager{name: "npm"},
+			[]string{"install", "--omit=dev", "--prefix", "build"},
+			[]string{"run", "build", "--prefix", "app"},
+		},
+		{
+			&nodePackageManager{name: "pnpm", lockFile: "pnpm-lock.yaml"},
+			[]string{"install", "--prod", "--frozen-lockfile", "--config.node-linker=hoisted", "--dir", "build"},
+			[]string{"run", "build", "--dir", "app"},
+		},
+		{
+			&nodePackageManager{name: "yarn", lockFile: "yarn.lock"},
+			[]string{"install", "--production", "--frozen-lockfile", "--cwd", "build"},
+			[]string{"run", "build", "--cwd", "app"},
+		},
+	}
+	for _, test := range tests {
+		fake := useFakeRunner(t)
+		fake.Expect(test.manager.name, test.install...)
+		fake.Expect(test.manager.name, test.run...)
+
+		if err := test.manager.installProduction("build"); err != nil {
+			t.Errorf("%s: installProduction() error = %v", test.manager.name, err)
+		}
+		if err := test.manager.run("app", "build"); err != nil {
+			t.Errorf("%s: run() error = %v", test.manager.name, err)
+		}
+	}
+}
+
+func TestAddNodeLambdaToArchive(t *testing.T) {
+	directory := writeProject(t, map[string]string{
+		"package.json":                     `{"dependencies": {"uuid": "9"}}`,
+		"package-lock.json":                "{}",
+		"src/index.ts":                     "export const handler = async () => {}",
+		"dist/index.js":                    "exports.handler = async () => {}",
+		"node_modules/typescript/index.js": "",
+	})
+	cfg := newRuntimeConfig("nodejs20.x")
+	cfg.Config.NodeBuild = &config.NodeBuild{BuildScript: "build", OutputDirectory: "dist"}
+
+	// The build script runs in the project, and the production dependencies
+	// are installed into a separate directory
+	commands := []string{}
+	previous := cli.GetRunner()
+	cli.SetRunner(funcRunner(func(cmd *cli.Command) ([]byte, error) {
+		commands = append(commands, cmd.Name+" "+cmd.Args[0])
+		if cmd.Args[0] == "ci" {
+			buildDirectory := cmd.Args[len(cmd.Args)-1]
+			if _, err := os.Stat(filepath.Join(buildDirectory, "package-lock.json")); err != nil {
+				t.Errorf("the lock file was not copied to the build directory: %v", err)
+			}
+			modulePath := filepath.Join(buildDirectory, "node_modules", "uuid", "index.js")
+			if err := os.MkdirAll(filepath.Dir(modulePath), 0755); err != nil {
+				return nil, err
+			}
+			return nil, os.WriteFile(modulePath, []byte(""), 0644)
+		}
+		return nil, nil
+	}))
+	defer cli.SetRunner(previous)
+
+	archive := newDeploymentArchive()
+	if err := addNodeLambdaToArchive(archive, directory, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"npm run", "npm ci"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("commands = %v, want %v", commands, want)
+	}
+	names := []string{}
+	for name := range archive.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{"dist/index.js", "node_modules/uuid/index.js", "package-lock.json", "package.json", "src/index.ts"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %s, want %s", strings.Join(names, ", "), strings.Join(want, ", "))
+	}
+}
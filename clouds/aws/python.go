@@ -0,0 +1,327 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+// PythonManager finds the packages that a Python function depends on, either
+// in the site-packages of a local environment, or by installing them from
+// the requirements that the manager exports
+type PythonManager interface {
+	// Name is the python_manager value in kettle.json
+	Name() string
+
+	// Detect returns true if the project looks like it uses the manager
+	Detect(directory string) bool
+
+	// SitePackages returns the directory of packages to add to the deployment archive
+	SitePackages(directory string, cfg *config.Config, architecture string) (string, error)
+}
+
+// pythonManagers are in the order that they are detected in, when
+// kettle.json does not have a python_manager: lock files first, then
+// requirements files, and lastly local environments
+var pythonManagers = []PythonManager{
+	uvManager{},
+	poetryManager{},
+	pipenvManager{},
+	pipManager{},
+	venvManager{},
+	pyenvManager{},
+	condaManager{},
+}
+
+// getPythonManager returns the python_manager in kettle.json, or
+// detects it from the project's files if it has not been set
+func getPythonManager(directory string, cfg *config.Config) (PythonManager, error) {
+	for _, manager := range pythonManagers {
+		if cfg.Config.PythonManager == "" && manager.Detect(directory) {
+			fmt.Printf("🐍  Detected python_manager: %s\n", manager.Name())
+			return manager, nil
+		}
+		if cfg.Config.PythonManager == manager.Name() {
+			return manager, nil
+		}
+	}
+	if cfg.Config.PythonManager == "" {
+		return nil, cli.NewConfigError(fmt.Errorf("could not detect the python_manager; set it in kettle.json to one of: %s",
+			strings.Join(supportedPythonManagers(), ", "),
+		))
+	}
+	return nil, cli.NewConfigError(fmt.Errorf("unknown python_manager: %s (%s)",
+		cfg.Config.PythonManager,
+		strings.Join(supportedPythonManagers(), ", "),
+	))
+}
+
+func supportedPythonManagers() []string {
+	names := []string{}
+	for _, manager := range pythonManagers {
+		names = append(names, manager.Name())
+	}
+	return names
+}
+
+func fileExists(directory string, fileNames ...string) bool {
+	for _, fileName := range fileNames {
+		if _, err := os.Stat(path.Join(directory, fileName)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTOMLTable returns true if a TOML file in the directory has a table (e.g. tool.poetry)
+func hasTOMLTable(directory string, fileName string, table string) bool {
+	contents, err := os.ReadFile(path.Join(directory, fileName))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		line, _ = scanTOMLLine(line)
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.Trim(line, "[] ") == table {
+			return true
+		}
+	}
+	return false
+}
+
+// pipManager installs the requirements in requirements.txt, pyproject.toml or Pipfile.lock
+type pipManager struct{}
+
+func (pipManager) Name() string {
+	return "pip"
+}
+
+func (pipManager) Detect(directory string) bool {
+	return fileExists(directory, "requirements.txt") || hasTOMLTable(directory, "pyproject.toml", "project")
+}
+
+func (pipManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	requirements, err := readPythonRequirements(directory)
+	if err != nil {
+		return "", err
+	}
+	return buildPythonDependencies(directory, cfg, architecture, requirements)
+}
+
+// poetryManager installs the main dependencies in poetry.lock
+// https://python-poetry.org/docs/cli/#export
+type poetryManager struct{}
+
+func (poetryManager) Name() string {
+	return "poetry"
+}
+
+func (poetryManager) Detect(directory string) bool {
+	return fileExists(directory, "poetry.lock") || hasTOMLTable(directory, "pyproject.toml", "tool.poetry")
+}
+
+func (poetryManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	// Poetry 2 needs the poetry-plugin-export plugin
+	output, err := cli.ExecuteWithResult("poetry", []string{
+		"export",
+		"--format", "requirements.txt",
+		"--without-hashes",
+		"--only", "main",
+	}, "Exporting requirements from poetry")
+	if err != nil {
+		return "", err
+	}
+	return buildPythonDependencies(directory, cfg, architecture, &pythonRequirements{
+		source:   "poetry.lock",
+		contents: output,
+	})
+}
+
+// pipenvManager installs the default (non-dev) packages in Pipfile.lock
+type pipenvManager struct{}
+
+func (pipenvManager) Name() string {
+	return "pipenv"
+}
+
+func (pipenvManager) Detect(directory string) bool {
+	return fileExists(directory, "Pipfile.lock", "Pipfile")
+}
+
+func (pipenvManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	requirements, err := readRequirementsFile(directory, "Pipfile.lock")
+	if err != nil {
+		return "", err
+	}
+	if requirements == nil {
+		return "", cli.NewConfigError(fmt.Errorf("pipenv needs a Pipfile.lock (run pipenv lock)"))
+	}
+	return buildPythonDependencies(directory, cfg, architecture, requirements)
+}
+
+// uvManager installs the (non-dev) dependencies in uv.lock
+// https://docs.astral.sh/uv/reference/cli/#uv-export
+type uvManager struct{}
+
+func (uvManager) Name() string {
+	return "uv"
+}
+
+func (uvManager) Detect(directory string) bool {
+	return fileExists(directory, "uv.lock")
+}
+
+func (uvManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	output, err := cli.ExecuteWithResult("uv", []string{
+		"export",
+		"--format", "requirements-txt",
+		"--frozen",
+		"--no-dev",
+		"--no-hashes",
+		"--no-emit-project",
+	}, "Exporting requirements from uv")
+	if err != nil {
+		return "", err
+	}
+	return buildPythonDependencies(directory, cfg, architecture, &pythonRequirements{
+		source:   "uv.lock",
+		contents: output,
+	})
+}
+
+// venvManager adds the site-packages of a virtual environment in the
+// project's venv/ or .venv/ directory (python -m venv venv)
+type venvManager struct{}
+
+// Directories that virtual environments are looked for in
+var venvDirectories = []string{"venv", ".venv"}
+
+func (venvManager) Name() string {
+	return "venv"
+}
+
+func (venvManager) Detect(directory string) bool {
+	for _, venv := range venvDirectories {
+		if fileExists(path.Join(directory, venv), "pyvenv.cfg") {
+			return true
+		}
+	}
+	return false
+}
+
+func (venvManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	for _, venv := range venvDirectories {
+		if !fileExists(path.Join(directory, venv), "pyvenv.cfg") {
+			continue
+		}
+
+		// The site-packages of the runtime's version, or else of any version
+		sitePackages := path.Join(directory, venv, "lib", cfg.Config.Runtime, "site-packages")
+		if _, err := os.Stat(sitePackages); err != nil {
+			matches, err := filepath.Glob(path.Join(directory, venv, "lib", "python*", "site-packages"))
+			if err != nil {
+				return "", err
+			}
+			if len(matches) == 0 {
+				return "", cli.NewConfigError(fmt.Errorf("the virtual environment in %s does not have a site-packages directory", venv))
+			}
+			sitePackages = matches[0]
+			cli.Warn("The virtual environment in %s is for %s, but the runtime is %s",
+				venv,
+				filepath.Base(filepath.Dir(sitePackages)),
+				cfg.Config.Runtime,
+			)
+		}
+		fmt.Printf("🔒  Adding site-packages from the '%s' virtual environment.\n", venv)
+		return sitePackages, nil
+	}
+	return "", cli.NewConfigError(fmt.Errorf("could not find a virtual environment in %s", strings.Join(venvDirectories, " or ")))
+}
+
+// pyenvManager adds the site-packages of the local pyenv version
+type pyenvManager struct{}
+
+func (pyenvManager) Name() string {
+	return "pyenv"
+}
+
+func (pyenvManager) Detect(directory string) bool {
+	return fileExists(directory, ".python-version")
+}
+
+func (pyenvManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	return getPyenvSitePackagesDirectory(cfg.Config.Runtime)
+}
+
+// condaManager adds the site-packages of the active conda environment
+type condaManager struct{}
+
+func (condaManager) Name() string {
+	return "conda"
+}
+
+func (condaManager) Detect(directory string) bool {
+	_, ok := os.LookupEnv("CONDA_DEFAULT_ENV")
+	return ok
+}
+
+func (condaManager) SitePackages(directory string, cfg *config.Config, architecture string) (string, error) {
+	return getCondaSitePackagesDirectory(cfg.Config.Runtime)
+}
+
+func getPyenvSitePackagesDirectory(pythonVersion string) (string, error) {
+	pyenvRoot, err := cli.ExecuteWithResult("pyenv", []string{
+		"root",
+	}, "Finding pyenv root")
+	if err != nil {
+		return "", err
+	}
+
+	pyenvLocal, err := cli.ExecuteWithResult("pyenv", []string{
+		"local",
+	}, "Finding pyenv local version")
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("🔒  Adding site-packages from the pyenv '%s' environment.\n", strings.Trim(string(pyenvLocal), "\n"))
+	return fmt.Sprintf("%s/versions/%s/lib/%s/site-packages/",
+		strings.Trim(string(pyenvRoot), "\n"),
+		strings.Trim(string(pyenvLocal), "\n"),
+		pythonVersion,
+	), nil
+}
+
+func getCondaSitePackagesDirectory(pythonVersion string) (string, error) {
+	condaRoot, err := cli.ExecuteWithResult("conda", []string{
+		"info",
+		"--base",
+	}, "Finding conda root")
+	if err != nil {
+		return "", err
+	}
+
+	// Assumes that the conda env is active
+	condaLocal := os.Getenv("CONDA_DEFAULT_ENV")
+	fmt.Printf("🔒  Adding site-packages from the conda '%s' environment.\n", condaLocal)
+	if condaLocal == "base" {
+		useBaseConda, err := cli.PromptToConfirm("aws.use_conda_base", "The conda base environment is active. Continue")
+		if err != nil {
+			return "", err
+		}
+		if !useBaseConda {
+			return "", cli.NewUserInputError("please activate the conda environment for your project before deploying")
+		}
+	}
+
+	return fmt.Sprintf("%s/envs/%s/lib/%s/site-packages/",
+		strings.Trim(string(condaRoot), "\n"),
+		strings.Trim(condaLocal, "\n"),
+		pythonVersion,
+	), nil
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetPythonManager(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"uv", map[string]string{"uv.lock": "", "pyproject.toml": "[project]\n"}, "uv"},
+		{"poetry lock file", map[string]string{"poetry.lock": "", "requirements.txt": ""}, "poetry"},
+		{"poetry table", map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"app\"\n"}, "poetry"},
+		{"pipenv", map[string]string{"Pipfile": ""}, "pipenv"},
+		{"requirements", map[string]string{"requirements.txt": "requests\n"}, "pip"},
+		{"project table", map[string]string{"pyproject.toml": "[project]\ndependencies = []\n"}, "pip"},
+		{"venv", map[string]string{".venv/pyvenv.cfg": ""}, "venv"},
+		{"pyenv", map[string]string{".python-version": "3.12\n"}, "pyenv"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := writeProject(t, test.files)
+			manager, err := getPythonManager(directory, newRuntimeConfig("python3.12"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if manager.Name() != test.want {
+				t.Errorf("getPythonManager() = %s, want %s", manager.Name(), test.want)
+			}
+		})
+	}
+}
+
+func TestGetPythonManagerWithoutProjectTable(t *testing.T) {
+	// An active conda environment would be detected instead
+	setTestEnv(t, "CONDA_DEFAULT_ENV", "")
+	os.Unsetenv("CONDA_DEFAULT_ENV")
+
+	// A pyproject.toml that only configures tools does not have any dependencies to install
+	directory := writeProject(t, map[string]string{
+		"pyproject.toml": "# [project]\n[tool.black]\nline-length = 100\n",
+	})
+	if manager, err := getPythonManager(directory, newRuntimeConfig("python3.12")); err == nil {
+		t.Errorf("getPythonManager() = %s, want an error", manager.Name())
+	}
+}
+
+func TestGetPythonManagerFromConfig(t *testing.T) {
+	directory := writeProject(t, map[string]string{"requirements.txt": ""})
+
+	cfg := newRuntimeConfig("python3.12")
+	cfg.Config.PythonManager = "uv"
+	manager, err := getPythonManager(directory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manager.Name() != "uv" {
+		t.Errorf("getPythonManager() = %s, want uv", manager.Name())
+	}
+
+	cfg.Config.PythonManager = "hatch"
+	if _, err := getPythonManager(directory, cfg); err == nil {
+		t.Error("getPythonManager() with an unknown python_manager, want an error")
+	}
+}
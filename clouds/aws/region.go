@@ -8,6 +8,10 @@ import (
 )
 
 func SetDeploymentRegion(stg *settings.AWSSettings, overwrite bool) error {
+	return setDeploymentRegion(stg, overwrite, "aws.region")
+}
+
+func setDeploymentRegion(stg *settings.AWSSettings, overwrite bool, promptKey string) error {
 	if !overwrite {
 		if stg.DeploymentRegion != "" {
 			return nil
@@ -19,7 +23,7 @@ func SetDeploymentRegion(stg *settings.AWSSettings, overwrite bool) error {
 		return err
 	}
 
-	region, err := cli.PromptForValue("Deployment region", regions, false)
+	region, err := cli.PromptForValue(promptKey, "Deployment region", regions, false)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Rollback points the function's alias to the version that was deployed before
+// the live one, or to the given version
+func (AWSLambdaFunction) Rollback(directory string, cfg *config.Config, stg *settings.Settings, env string, history *config.History, to string) (*config.Deployment, error) {
+	_, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	target := newLambdaTarget(cfg, env)
+	alias, err := getAlias(target)
+	if err != nil {
+		return nil, err
+	}
+	if alias == nil {
+		return nil, cli.NewUserInputError("%s does not have a %s alias; deploy it again to publish a version", target.name, liveAlias)
+	}
+
+	version := to
+	if version == "" {
+		previous := history.Previous(env, alias.FunctionVersion, getDeploymentVersion)
+		if previous == nil {
+			return nil, cli.NewUserInputError("there is no earlier version of %s to roll back to", target.name)
+		}
+		version = getDeploymentVersion(previous)
+	}
+	if version == alias.FunctionVersion {
+		return nil, cli.NewUserInputError("version %s of %s is already live", version, target.name)
+	}
+
+	fmt.Printf("⏪  Rolling back: %s from version %s to %s\n", target.name, alias.FunctionVersion, version)
+	err = cli.Execute("aws", []string{
+		"lambda",
+		"update-alias",
+		"--function-name", target.name,
+		"--name", liveAlias,
+		"--function-version", version,
+	}, fmt.Sprintf("Pointing the %s alias to version %s", liveAlias, version))
+	if err != nil {
+		return nil, err
+	}
+
+	// The version's ARN is the alias's ARN, with the version instead of the alias
+	deployment := &config.Deployment{
+		LambdaVersionArn: fmt.Sprintf("%s:%s", strings.TrimSuffix(alias.AliasArn, ":"+liveAlias), version),
+	}
+	if previous := history.Find(env, version, getDeploymentVersion); previous != nil {
+		deployment.GitSHA = previous.GitSHA
+		deployment.PackageHash = previous.PackageHash
+	}
+	return deployment, nil
+}
+
+func getDeploymentVersion(deployment *config.Deployment) string {
+	if deployment.LambdaVersionArn == "" {
+		return ""
+	}
+	return getVersionFromArn(deployment.LambdaVersionArn)
+}
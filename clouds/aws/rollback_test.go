@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+const testFunctionArn = "arn:aws:lambda:eu-west-1:111111111111:function:hello"
+
+func TestRollback(t *testing.T) {
+	history := &config.History{}
+	history.Add(&config.Deployment{LambdaVersionArn: testFunctionArn + ":1", GitSHA: "aaa", PackageHash: "one"})
+	history.Add(&config.Deployment{LambdaVersionArn: testFunctionArn + ":2", GitSHA: "bbb", PackageHash: "two"})
+
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "lambda", "get-alias", "--function-name", "hello", "--name", liveAlias, "--output", "json").Stdout =
+		`{"AliasArn": "` + testFunctionArn + `:` + liveAlias + `", "FunctionVersion": "2"}`
+	fake.Expect("aws", "lambda", "update-alias", "--function-name", "hello", "--name", liveAlias, "--function-version", "1")
+
+	cfg := &config.Config{ProjectName: "hello"}
+	deployment, err := (AWSLambdaFunction{}).Rollback(t.TempDir(), cfg, &settings.Settings{AWS: &settings.AWSSettings{}}, "", history, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployment.LambdaVersionArn != testFunctionArn+":1" || deployment.GitSHA != "aaa" || deployment.PackageHash != "one" {
+		t.Errorf("Rollback() = %+v, want version 1 from aaa", deployment)
+	}
+}
+
+func TestRollbackWithoutEarlierVersion(t *testing.T) {
+	history := &config.History{}
+	history.Add(&config.Deployment{LambdaVersionArn: testFunctionArn + ":1"})
+
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "lambda", "get-alias", "--function-name", "hello", "--name", liveAlias, "--output", "json").Stdout =
+		`{"AliasArn": "` + testFunctionArn + `:` + liveAlias + `", "FunctionVersion": "1"}`
+
+	cfg := &config.Config{ProjectName: "hello"}
+	if _, err := (AWSLambdaFunction{}).Rollback(t.TempDir(), cfg, &settings.Settings{AWS: &settings.AWSSettings{}}, "", history, ""); err == nil {
+		t.Error("Rollback() did not return an error")
+	}
+}
+
+func TestGetPackageHash(t *testing.T) {
+	directory := writeProject(t, map[string]string{
+		"main.py":       "def handler(event, context): pass",
+		".kettleignore": "notes.md\n",
+	})
+	before, err := getPackageHash(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build outputs and ignored files do not change the hash
+	for name, contents := range map[string]string{"deployment.zip": "zip", "notes.md": "notes"} {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if after, _ := getPackageHash(directory); after != before {
+		t.Errorf("getPackageHash() changed after adding ignored files")
+	}
+
+	if err := os.WriteFile(filepath.Join(directory, "main.py"), []byte("def handler(event, context): return 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := getPackageHash(directory); after == before {
+		t.Errorf("getPackageHash() did not change after changing main.py")
+	}
+}
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+const (
+	// Go functions run on the OS-only runtime, since go1.x is deprecated
+	// https://docs.aws.amazon.com/lambda/latest/dg/lambda-golang.html
+	goLambdaRuntime = "provided.al2023"
+	goLambdaHandler = "bootstrap"
+
+	// The default instruction set architecture of a Lambda function
+	defaultArchitecture = "x86_64"
+)
+
+// IsGoRuntime returns true for Go runtimes (e.g. go1.x, go116) and
+// the OS-only runtimes that Go functions are deployed to
+func IsGoRuntime(runtime string) bool {
+	return strings.HasPrefix(runtime, "go") || strings.HasPrefix(runtime, "provided")
+}
+
+// getLambdaRuntime returns the --handler and --runtime of a function, which
+// change based on the programming language
+func getLambdaRuntime(cfg *config.Config) (string, string, error) {
+	switch {
+	case strings.HasPrefix(cfg.Config.Runtime, "python"):
+		return fmt.Sprintf("main.%s", cfg.Config.EntryFunction), cfg.Config.Runtime, nil
+	case strings.HasPrefix(cfg.Config.Runtime, "nodejs"):
+		return getNodeHandler(cfg), cfg.Config.Runtime, nil
+	case strings.HasPrefix(cfg.Config.Runtime, "provided"):
+		return goLambdaHandler, cfg.Config.Runtime, nil
+	case strings.HasPrefix(cfg.Config.Runtime, "go"):
+		return goLambdaHandler, goLambdaRuntime, nil
+	}
+	return "", "", cli.NewConfigError(fmt.Errorf("unknown runtime: %s", cfg.Config.Runtime))
+}
+
+// GetLambdaHandler returns the handler of a function, as it is set in the _HANDLER
+// environment variable of the Lambda runtime
+func GetLambdaHandler(cfg *config.Config) (string, error) {
+	handler, _, err := getLambdaRuntime(cfg)
+	return handler, err
+}
+
+// getFunctionArchitecture returns the architecture of an existing function
+func getFunctionArchitecture(function *lambdaFunction) string {
+	if function != nil && len(function.Configuration.Architectures) > 0 {
+		return function.Configuration.Architectures[0]
+	}
+	return defaultArchitecture
+}
+
+// getLambdaArchitecture returns the instruction set that a function is deployed to: the
+// architecture in kettle.json, or else the architecture of the existing function
+func getLambdaArchitecture(cfg *config.Config, function *lambdaFunction) (string, error) {
+	switch cfg.Config.Architecture {
+	case "":
+		return getFunctionArchitecture(function), nil
+	case "x86_64", "arm64":
+		return cfg.Config.Architecture, nil
+	}
+	return "", cli.NewConfigError(fmt.Errorf("unknown architecture: %s (x86_64 or arm64)", cfg.Config.Architecture))
+}
+
+// getGoArch returns the GOARCH that builds binaries for a Lambda architecture
+func getGoArch(architecture string) string {
+	if architecture == "arm64" {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// getDockerPlatform returns the docker --platform that builds images for a Lambda architecture
+func getDockerPlatform(architecture string) string {
+	return fmt.Sprintf("linux/%s", getGoArch(architecture))
+}
+
+// hasRuntimeChanged returns true if a function's runtime or handler differ from
+// kettle.json, e.g. for a Go function that was deployed to go1.x
+func hasRuntimeChanged(function *lambdaFunction, cfg *config.Config) (bool, error) {
+	handler, runtime, err := getLambdaRuntime(cfg)
+	if err != nil {
+		return false, err
+	}
+	return function.Configuration.Runtime != runtime || function.Configuration.Handler != handler, nil
+}
+
+// updateFunctionRuntime sets a function's runtime and handler; it is called once
+// the function's code has been updated, so that the new runtime has its handler
+func updateFunctionRuntime(function *lambdaFunction, target *lambdaTarget, cfg *config.Config) error {
+	handler, runtime, err := getLambdaRuntime(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔀  Updating runtime: %s -> %s\n", function.Configuration.Runtime, runtime)
+	err = cli.Execute("aws", []string{
+		"lambda",
+		"update-function-configuration",
+		"--function-name", target.name,
+		"--runtime", runtime,
+		"--handler", handler,
+	}, "Updating lambda function runtime")
+	if err != nil {
+		return err
+	}
+	return waitForLambda("function-updated", target)
+}
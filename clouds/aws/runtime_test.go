@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/config"
+)
+
+func newRuntimeConfig(runtime string) *config.Config {
+	cfg := &config.Config{ProjectName: "hello"}
+	cfg.Config.Runtime = runtime
+	cfg.Config.EntryFunction = "handler"
+	return cfg
+}
+
+func TestGetLambdaRuntime(t *testing.T) {
+	tests := []struct {
+		runtime     string
+		wantHandler string
+		wantRuntime string
+		wantErr     bool
+	}{
+		{"python3.12", "main.handler", "python3.12", false},
+		{"nodejs20.x", "index.handler", "nodejs20.x", false},
+		{"go1.x", "bootstrap", "provided.al2023", false},
+		{"go121", "bootstrap", "provided.al2023", false},
+		{"provided.al2", "bootstrap", "provided.al2", false},
+		{"provided.al2023", "bootstrap", "provided.al2023", false},
+		{"ruby3.2", "", "", true},
+	}
+	for _, test := range tests {
+		handler, runtime, err := getLambdaRuntime(newRuntimeConfig(test.runtime))
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("getLambdaRuntime(%s) did not return an error", test.runtime)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if handler != test.wantHandler || runtime != test.wantRuntime {
+			t.Errorf("getLambdaRuntime(%s) = %s, %s; want %s, %s", test.runtime, handler, runtime, test.wantHandler, test.wantRuntime)
+		}
+	}
+}
+
+func newTestFunction(t *testing.T, configuration string) *lambdaFunction {
+	function := &lambdaFunction{}
+	if err := json.Unmarshal([]byte(`{"Configuration": `+configuration+`}`), function); err != nil {
+		t.Fatal(err)
+	}
+	return function
+}
+
+func TestHasRuntimeChanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		runtime  string
+		want     bool
+	}{
+		{"go1.x function", `{"Runtime": "go1.x", "Handler": "main"}`, "go1.x", true},
+		{"moved to provided.al2023", `{"Runtime": "provided.al2023", "Handler": "bootstrap"}`, "go1.x", false},
+		{"python", `{"Runtime": "python3.12", "Handler": "main.handler"}`, "python3.12", false},
+		{"new python version", `{"Runtime": "python3.11", "Handler": "main.handler"}`, "python3.12", true},
+	}
+	for _, test := range tests {
+		changed, err := hasRuntimeChanged(newTestFunction(t, test.function), newRuntimeConfig(test.runtime))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != test.want {
+			t.Errorf("%s: hasRuntimeChanged() = %t, want %t", test.name, changed, test.want)
+		}
+	}
+}
+
+func TestUpdateFunctionRuntime(t *testing.T) {
+	fake := useFakeRunner(t)
+	fake.Expect("aws", "lambda", "update-function-configuration", "--function-name", "hello",
+		"--runtime", "provided.al2023", "--handler", "bootstrap")
+	fake.Expect("aws", "lambda", "wait", "function-updated", "--function-name", "hello")
+
+	function := newTestFunction(t, `{"Runtime": "go1.x", "Handler": "main"}`)
+	if err := updateFunctionRuntime(function, &lambdaTarget{name: "hello"}, newRuntimeConfig("go1.x")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetLambdaArchitecture(t *testing.T) {
+	arm := newTestFunction(t, `{"Architectures": ["arm64"]}`)
+	tests := []struct {
+		name         string
+		architecture string
+		function     *lambdaFunction
+		want         string
+		wantErr      bool
+	}{
+		{"default", "", nil, "x86_64", false},
+		{"existing function", "", arm, "arm64", false},
+		{"kettle.json", "arm64", nil, "arm64", false},
+		{"kettle.json changes the function", "x86_64", arm, "x86_64", false},
+		{"unknown", "aarch64", nil, "", true},
+	}
+	for _, test := range tests {
+		cfg := newRuntimeConfig("python3.12")
+		cfg.Config.Architecture = test.architecture
+		architecture, err := getLambdaArchitecture(cfg, test.function)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: getLambdaArchitecture() did not return an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if architecture != test.want {
+			t.Errorf("%s: getLambdaArchitecture() = %s, want %s", test.name, architecture, test.want)
+		}
+	}
+}
+
+func TestGetGoArch(t *testing.T) {
+	for architecture, want := range map[string]string{"x86_64": "amd64", "arm64": "arm64"} {
+		if got := getGoArch(architecture); got != want {
+			t.Errorf("getGoArch(%s) = %s, want %s", architecture, got, want)
+		}
+		if got := getDockerPlatform(architecture); got != "linux/"+want {
+			t.Errorf("getDockerPlatform(%s) = %s, want linux/%s", architecture, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds/aws/apigateway"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+func (AWSLambdaFunction) Status(directory string, cfg *config.Config, stg *settings.Settings, env string) (*cli.Report, error) {
+	stg, restore, err := useEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	target := newLambdaTarget(cfg, env)
+	report := cli.NewReport(cfg.ProjectName, env, "AWS Lambda function")
+	report.Set("function_name", target.name)
+
+	function, err := getLambdaFunction(target.name)
+	if err != nil {
+		return nil, err
+	}
+	if function == nil {
+		if target.deployment.CodeSha256 != "" || target.deployment.RestApiResourceID != "" {
+			report.Mismatch("kettle.json has deployment settings, but the function %s does not exist", target.name)
+		}
+		return report, nil
+	}
+
+	report.Deployed = true
+	configuration := function.Configuration
+	report.Set("function_arn", configuration.FunctionArn)
+	report.Set("runtime", configuration.Runtime)
+	report.Set("handler", configuration.Handler)
+	report.Set("architecture", getFunctionArchitecture(function))
+	report.Set("memory_mb", strconv.Itoa(configuration.MemorySize))
+	report.Set("timeout_seconds", strconv.Itoa(configuration.Timeout))
+	if function.Concurrency.ReservedConcurrentExecutions != nil {
+		report.Set("reserved_concurrency", strconv.Itoa(*function.Concurrency.ReservedConcurrentExecutions))
+	}
+	report.Set("last_modified", configuration.LastModified)
+	report.Set("code_sha256", configuration.CodeSha256)
+
+	alias, err := getAlias(target)
+	if err != nil {
+		return nil, err
+	}
+	if alias != nil {
+		report.Set("live_version", alias.FunctionVersion)
+	} else {
+		report.Mismatch("the function does not have a %s alias (deploy it again to publish a version)", liveAlias)
+	}
+
+	// Compare the function with kettle.json
+	if target.deployment.CodeSha256 != "" && target.deployment.CodeSha256 != configuration.CodeSha256 {
+		report.Mismatch("the deployed code (%s) is not the code that kettle last deployed (%s)", configuration.CodeSha256, target.deployment.CodeSha256)
+	}
+	if configuration.PackageType == "Image" {
+		report.Set("image_uri", function.Code.ImageUri)
+		if target.deployment.ImageURI != "" && target.deployment.ImageURI != function.Code.ImageUri {
+			report.Mismatch("the deployed image (%s) is not the image that kettle last deployed (%s)", function.Code.ImageUri, target.deployment.ImageURI)
+		}
+	} else if handler, runtime, err := getLambdaRuntime(cfg); err == nil {
+		if runtime != configuration.Runtime {
+			report.Mismatch("the runtime is %s, but kettle.json has %s", configuration.Runtime, runtime)
+		}
+		if handler != configuration.Handler {
+			report.Mismatch("the handler is %s, but kettle.json has %s", configuration.Handler, handler)
+		}
+	}
+	if cfg.Config.Architecture != "" && cfg.Config.Architecture != getFunctionArchitecture(function) {
+		report.Mismatch("the architecture is %s, but kettle.json has %s", getFunctionArchitecture(function), cfg.Config.Architecture)
+	}
+	if resources, err := cfg.ResolveResources(env); err == nil {
+		if resources.MemoryMB != 0 && resources.MemoryMB != configuration.MemorySize {
+			report.Mismatch("the memory is %d MB, but kettle.json has %d MB", configuration.MemorySize, resources.MemoryMB)
+		}
+		if resources.TimeoutSeconds != 0 && resources.TimeoutSeconds != configuration.Timeout {
+			report.Mismatch("the timeout is %ds, but kettle.json has %ds", configuration.Timeout, resources.TimeoutSeconds)
+		}
+	}
+
+	if err := addRestAPIStatus(report, target, stg); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// addRestAPIStatus reports the function's resource, methods & permissions in the REST API
+func addRestAPIStatus(report *cli.Report, target *lambdaTarget, stg *settings.Settings) error {
+	if target.deployment.RestApiResourceID == "" || stg.AWS.RestApiID == "" {
+		report.Set("rest_api", "(not added to a REST API)")
+		return nil
+	}
+
+	report.Set("rest_api", stg.AWS.RestApiID)
+	resource, err := apigateway.GetResource(stg, target.deployment.RestApiResourceID)
+	if err != nil {
+		return err
+	}
+	if resource == nil {
+		report.Mismatch("the REST API resource %s does not exist", target.deployment.RestApiResourceID)
+		return nil
+	}
+	report.Set("rest_api_resource", fmt.Sprintf("%s (%s)", resource.Path, resource.ID))
+	report.Set("rest_api_methods", strings.Join(resource.Methods, ", "))
+	if !resource.HasPostMethod {
+		report.Mismatch("the REST API resource %s does not have a POST method", resource.Path)
+	}
+	report.Set("url", getEndpointURL(target, stg))
+
+	statementIDs, err := getPolicyStatementIDs(target)
+	if err != nil {
+		return err
+	}
+	report.Set("permissions", strings.Join(statementIDs, ", "))
+	for _, permission := range getInvocationPermissions(target) {
+		if !contains(statementIDs, permission[0]) {
+			report.Mismatch("the function does not have the %s permission", permission[0])
+		}
+	}
+	return nil
+}
+
+// getPolicyStatementIDs returns the IDs of the statements in the alias's resource-based policy
+func getPolicyStatementIDs(target *lambdaTarget) ([]string, error) {
+	output, err := cli.ExecuteWithResult("aws", []string{
+		"lambda",
+		"get-policy",
+		"--function-name", target.name,
+		"--qualifier", liveAlias,
+		"--output", "json",
+	}, "Querying for lambda function permissions")
+	if err != nil {
+		if cli.IsExitCode(err, 254) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	// The policy is a JSON document in a string
+	var result struct {
+		Policy string `json:"Policy"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, err
+	}
+	var policy struct {
+		Statement []struct {
+			Sid string `json:"Sid"`
+		} `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(result.Policy), &policy); err != nil {
+		return nil, err
+	}
+
+	statementIDs := []string{}
+	for _, statement := range policy.Statement {
+		statementIDs = append(statementIDs, statement.Sid)
+	}
+	return statementIDs, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds/aws/apigateway"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+const (
+	// The REST API invokes the version of a function that
+	// this alias points to; kettle rollback moves the alias
+	liveAlias = "live"
+)
+
+type lambdaVersion struct {
+	Version     string `json:"Version"`
+	FunctionArn string `json:"FunctionArn"`
+}
+
+type lambdaAlias struct {
+	AliasArn        string `json:"AliasArn"`
+	FunctionVersion string `json:"FunctionVersion"`
+}
+
+// publishVersion publishes the function's code & configuration as a version; Lambda
+// returns the latest version if nothing has changed since it was published. The
+// version is only published if the code has the codeSha256 (if it is set)
+func publishVersion(target *lambdaTarget, codeSha256 string) (*lambdaVersion, error) {
+	args := []string{
+		"lambda",
+		"publish-version",
+		"--function-name", target.name,
+		"--output", "json",
+	}
+	if codeSha256 != "" {
+		args = append(args, "--code-sha256", codeSha256)
+	}
+	output, err := cli.ExecuteWithResult("aws", args, "Publishing lambda function version")
+	if err != nil {
+		return nil, err
+	}
+
+	version := &lambdaVersion{}
+	if err := json.Unmarshal(output, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// getAlias returns nil if the alias does not exist
+func getAlias(target *lambdaTarget) (*lambdaAlias, error) {
+	output, err := cli.ExecuteWithResult("aws", []string{
+		"lambda",
+		"get-alias",
+		"--function-name", target.name,
+		"--name", liveAlias,
+		"--output", "json",
+	}, "Querying for lambda function alias")
+	if err != nil {
+		if cli.IsExitCode(err, 254) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	alias := &lambdaAlias{}
+	if err := json.Unmarshal(output, alias); err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
+
+// setAlias points the alias to a version, and returns true if it has changed
+func setAlias(target *lambdaTarget, version string, stg *settings.Settings) (bool, error) {
+	alias, err := getAlias(target)
+	if err != nil {
+		return false, err
+	}
+	if alias != nil && alias.FunctionVersion == version {
+		return false, nil
+	}
+
+	operation := "update-alias"
+	if alias == nil {
+		operation = "create-alias"
+	}
+	err = cli.Execute("aws", []string{
+		"lambda",
+		operation,
+		"--function-name", target.name,
+		"--name", liveAlias,
+		"--function-version", version,
+	}, fmt.Sprintf("Pointing the %s alias to version %s", liveAlias, version))
+	if err != nil {
+		return false, err
+	}
+
+	// Functions that were added to a REST API before they had an
+	// alias are moved to the alias, so that they can be rolled back
+	if alias == nil && target.deployment.RestApiResourceID != "" && stg.AWS.RestApiID != "" {
+		if err := addFunctionIntegration(target, stg); err != nil {
+			return false, err
+		}
+		if err := addInvocationPermission(target, stg); err != nil {
+			return false, err
+		}
+		if err := apigateway.Deploy(stg, target.stage); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// getVersionFromArn returns the version in a qualified ARN,
+// e.g. 3 in arn:aws:lambda:eu-west-1:123456789012:function:name:3
+func getVersionFromArn(arn string) string {
+	return arn[strings.LastIndex(arn, ":")+1:]
+}
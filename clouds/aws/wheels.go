@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+)
+
+// wheelPlatforms are the suffixes of the wheel platform tags that run on
+// each Lambda architecture (e.g. manylinux2014_x86_64, manylinux_2_17_aarch64)
+var wheelPlatforms = map[string]string{
+	"x86_64": "x86_64",
+	"arm64":  "aarch64",
+}
+
+// checkWheelArchitectures warns about installed packages with native code that is
+// built for a different platform than the function (e.g. macOS or x86_64 wheels
+// on arm64), since they are added to the archive but fail to import on Lambda
+func checkWheelArchitectures(sitePackages string, architecture string) error {
+	wheelFiles, err := filepath.Glob(filepath.Join(sitePackages, "*.dist-info", "WHEEL"))
+	if err != nil {
+		return err
+	}
+
+	incompatible := []string{}
+	for _, wheelFile := range wheelFiles {
+		tags, err := readWheelTags(wheelFile)
+		if err != nil {
+			return err
+		}
+		if len(tags) > 0 && !wheelSupports(tags, architecture) {
+			distInfo := filepath.Base(filepath.Dir(wheelFile))
+			incompatible = append(incompatible, strings.TrimSuffix(distInfo, ".dist-info"))
+		}
+	}
+	if len(incompatible) > 0 {
+		sort.Strings(incompatible)
+		cli.Warn("%d package(s) have native code that is not built for Lambda (linux %s): %s",
+			len(incompatible),
+			architecture,
+			strings.Join(incompatible, ", "),
+		)
+	}
+	return nil
+}
+
+// readWheelTags returns the Tag values of a WHEEL metadata file
+func readWheelTags(wheelFile string) ([]string, error) {
+	f, err := os.Open(wheelFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tags := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value := strings.TrimPrefix(scanner.Text(), "Tag:"); value != scanner.Text() {
+			tags = append(tags, strings.TrimSpace(value))
+		}
+	}
+	return tags, scanner.Err()
+}
+
+// wheelSupports returns true if any of a wheel's tags (<python>-<abi>-<platform>)
+// is for any platform, or for linux on the architecture
+func wheelSupports(tags []string, architecture string) bool {
+	for _, tag := range tags {
+		parts := strings.Split(tag, "-")
+		// Compressed tags have several platforms (e.g. manylinux1_x86_64.manylinux2010_x86_64)
+		for _, platform := range strings.Split(parts[len(parts)-1], ".") {
+			if platform == "any" {
+				return true
+			}
+			if strings.Contains(platform, "linux") && strings.HasSuffix(platform, wheelPlatforms[architecture]) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWheelSupports(t *testing.T) {
+	tests := []struct {
+		tag          string
+		architecture string
+		want         bool
+	}{
+		{"py3-none-any", "arm64", true},
+		{"cp312-cp312-manylinux_2_17_x86_64.manylinux2014_x86_64", "x86_64", true},
+		{"cp312-cp312-manylinux_2_17_x86_64.manylinux2014_x86_64", "arm64", false},
+		{"cp312-cp312-manylinux_2_17_aarch64.manylinux2014_aarch64", "arm64", true},
+		{"cp312-cp312-musllinux_1_1_aarch64", "arm64", true},
+		{"cp312-cp312-macosx_11_0_arm64", "arm64", false},
+		{"cp312-cp312-win_amd64", "x86_64", false},
+	}
+	for _, test := range tests {
+		if got := wheelSupports([]string{test.tag}, test.architecture); got != test.want {
+			t.Errorf("wheelSupports(%s, %s) = %t, want %t", test.tag, test.architecture, got, test.want)
+		}
+	}
+}
+
+func TestCheckWheelArchitectures(t *testing.T) {
+	sitePackages := writeProject(t, map[string]string{
+		"requests-2.31.0.dist-info/WHEEL": "Wheel-Version: 1.0\nTag: py3-none-any\n",
+		"numpy-1.26.0.dist-info/WHEEL":    "Wheel-Version: 1.0\nTag: cp312-cp312-manylinux_2_17_x86_64.manylinux2014_x86_64\n",
+		"orjson-3.9.0.dist-info/WHEEL":    "Wheel-Version: 1.0\nTag: cp312-cp312-macosx_11_0_arm64\n",
+		"local-0.1.dist-info/METADATA":    "Name: local\n",
+	})
+
+	tests := []struct {
+		architecture string
+		want         []string
+	}{
+		{"x86_64", []string{"orjson-3.9.0"}},
+		{"arm64", []string{"numpy-1.26.0", "orjson-3.9.0"}},
+	}
+	for _, test := range tests {
+		output := captureStdout(t, func() {
+			if err := checkWheelArchitectures(sitePackages, test.architecture); err != nil {
+				t.Fatal(err)
+			}
+		})
+		for _, name := range test.want {
+			if !strings.Contains(output, name) {
+				t.Errorf("%s: warning %q does not mention %s", test.architecture, output, name)
+			}
+		}
+		if strings.Contains(output, "requests") {
+			t.Errorf("%s: warning %q mentions a pure Python package", test.architecture, output)
+		}
+	}
+}
+
+// captureStdout returns what a function prints
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	stdout := os.Stdout
+	file, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = file
+	defer func() {
+		os.Stdout = stdout
+		file.Close()
+	}()
+
+	f()
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
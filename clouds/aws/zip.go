@@ -1,55 +1,67 @@
 package aws
 
 import (
-	"errors"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
 
 	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/ignore"
 )
 
 const (
 	deploymentArchiveName = "deployment.zip"
-	goBuildFileName       = "main"
+
+	// The provided.al2 & provided.al2023 runtimes run an executable named bootstrap
+	goBuildFileName = "bootstrap"
 )
 
-func createDeploymentArchive(cfg *config.Config) (string, error) {
+func createDeploymentArchive(directory string, cfg *config.Config, architecture string) (string, error) {
 	// Remove any existing deployment package
-	if err := removeDeploymentArchive(cfg); err != nil {
+	if err := removeDeploymentArchive(directory, cfg); err != nil {
 		return "", err
 	}
 
 	// Create a path to the deployment archive
-	rootDir, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	deploymentFile := path.Join(rootDir, deploymentArchiveName)
+	deploymentFile := path.Join(directory, deploymentArchiveName)
 
+	archive := newDeploymentArchive()
 	switch {
 	case strings.HasPrefix(cfg.Config.Runtime, "python"):
 		// https://docs.aws.amazon.com/lambda/latest/dg/python-package.html
-		if err := addPythonLambdaToArchive(deploymentFile, cfg); err != nil {
+		if err := addPythonLambdaToArchive(archive, directory, cfg, architecture); err != nil {
 			return "", err
 		}
-	case strings.HasPrefix(cfg.Config.Runtime, "go"):
+	case IsGoRuntime(cfg.Config.Runtime):
 		// https://docs.aws.amazon.com/lambda/latest/dg/golang-package.html
-		if err := addGoLambdaToArchive(deploymentFile, cfg); err != nil {
+		if err := addGoLambdaToArchive(archive, directory, architecture); err != nil {
 			return "", err
 		}
+	case strings.HasPrefix(cfg.Config.Runtime, "nodejs"):
+		if err := addNodeLambdaToArchive(archive, directory, cfg); err != nil {
+			return "", err
+		}
+	default:
+		return "", cli.NewConfigError(fmt.Errorf("unknown runtime: %s", cfg.Config.Runtime))
+	}
+
+	if err := archive.write(deploymentFile); err != nil {
+		return "", err
 	}
 	return deploymentFile, nil
 }
 
-func removeDeploymentArchive(cfg *config.Config) error {
-	if err := removeFile(deploymentArchiveName); err != nil {
+func removeDeploymentArchive(directory string, cfg *config.Config) error {
+	if err := removeFile(path.Join(directory, deploymentArchiveName)); err != nil {
 		return err
 	}
-	if strings.HasPrefix(cfg.Config.Runtime, "go") {
-		if err := removeFile(goBuildFileName); err != nil {
+	if IsGoRuntime(cfg.Config.Runtime) {
+		if err := removeFile(path.Join(directory, goBuildFileName)); err != nil {
 			return err
 		}
 	}
@@ -66,109 +78,36 @@ func removeFile(fileName string) error {
 	return os.Remove(fileName)
 }
 
-func addPythonLambdaToArchive(deploymentFile string, cfg *config.Config) error {
-	// Add the contents of the lambda function directory
-	err := cli.Execute("zip", []string{
-		"-g",
-		deploymentArchiveName,
-		"-r",
-		".",
-	}, "Adding code to the deployment archive")
+func addPythonLambdaToArchive(archive *deploymentArchive, directory string, cfg *config.Config, architecture string) error {
+	// Python builds need to add the site-packages contents
+	manager, err := getPythonManager(directory, cfg)
 	if err != nil {
 		return err
 	}
-
-	// Python builds need to add the site-packages contents
-	var sitePackages string
-	switch cfg.Config.PythonManager {
-	case "pyenv":
-		sitePackages, err = getPyenvSitePackagesDirectory(cfg.Config.Runtime)
-		if err != nil {
-			return err
-		}
-	case "conda":
-		sitePackages, err = getCondaSitePackagesDirectory(cfg.Config.Runtime)
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unknown python_manager: %s", cfg.Config.PythonManager)
+	sitePackages, err := manager.SitePackages(directory, cfg, architecture)
+	if err != nil {
+		return err
 	}
 
 	if _, err := os.Stat(sitePackages); !os.IsNotExist(err) {
-		// Change to the directory where the site-packages are stored
-		// So that we can add them to the zip file as a directory
-		rootDir, err := os.Getwd()
-		if err != nil {
+		if err := checkWheelArchitectures(sitePackages, architecture); err != nil {
 			return err
 		}
-
-		os.Chdir(sitePackages)
-		err = cli.Execute("zip", []string{
-			"-r",
-			deploymentFile,
-			".",
-		}, "Adding site-packages to the deployment archive")
-		if err != nil {
+		if err := archive.addDirectory(sitePackages, nil); err != nil {
 			return err
 		}
-
-		// Return to root directory to deploy the .zip file
-		os.Chdir(rootDir)
 	}
-	return nil
-}
 
-func getPyenvSitePackagesDirectory(pythonVersion string) (string, error) {
-	pyenvRoot, err := cli.ExecuteWithResult("pyenv", []string{
-		"root",
-	}, "Finding pyenv root")
+	// Add the contents of the lambda function directory (except for anything
+	// in .kettleignore); these replace any site-packages files with the same path
+	matcher, err := ignore.Load(directory)
 	if err != nil {
-		return "", err
-	}
-
-	pyenvLocal, err := cli.ExecuteWithResult("pyenv", []string{
-		"local",
-	}, "Finding pyenv local version")
-	if err != nil {
-		return "", err
-	}
-
-	fmt.Println(fmt.Sprintf("🔒  Adding site-packages from the pyenv '%s' environment.", string(pyenvLocal)))
-	return fmt.Sprintf("%s/versions/%s/lib/%s/site-packages/",
-		strings.Trim(string(pyenvRoot), "\n"),
-		strings.Trim(string(pyenvLocal), "\n"),
-		pythonVersion,
-	), nil
-}
-
-func getCondaSitePackagesDirectory(pythonVersion string) (string, error) {
-	condaRoot, err := cli.ExecuteWithResult("conda", []string{
-		"info",
-		"--base",
-	}, "Finding conda root")
-	if err != nil {
-		return "", err
-	}
-
-	// Assumes that the conda env is active
-	condaLocal := os.Getenv("CONDA_DEFAULT_ENV")
-	fmt.Println(fmt.Sprintf("🔒  Adding site-packages from the conda '%s' environment.", condaLocal))
-	if condaLocal == "base" {
-		useBaseConda := cli.PromptToConfirm("The conda base environment is active. Continue")
-		if !useBaseConda {
-			return "", errors.New("please activate the conda environment for your project before deploying")
-		}
+		return err
 	}
-
-	return fmt.Sprintf("%s/envs/%s/lib/%s/site-packages/",
-		strings.Trim(string(condaRoot), "\n"),
-		strings.Trim(condaLocal, "\n"),
-		pythonVersion,
-	), nil
+	return archive.addDirectory(directory, matcher.Match)
 }
 
-func addGoLambdaToArchive(deploymentFile string, cfg *config.Config) error {
+func addGoLambdaToArchive(archive *deploymentArchive, directory string, architecture string) error {
 	// go get github.com/aws/aws-lambda-go/lambda
 	err := cli.Execute("go", []string{
 		"get",
@@ -178,21 +117,41 @@ func addGoLambdaToArchive(deploymentFile string, cfg *config.Config) error {
 		return err
 	}
 
-	// Build the function for linux
+	// Build the function for linux; the lambda.norpc tag leaves out the
+	// RPC server that is only used by the deprecated go1.x runtime
+	goArch := getGoArch(architecture)
+	binaryPath := path.Join(directory, goBuildFileName)
 	err = cli.Execute("env", []string{
 		"GOOS=linux",
+		fmt.Sprintf("GOARCH=%s", goArch),
 		"CGO_ENABLED=0",
 		"go",
 		"build",
-		"-o", goBuildFileName,
-	}, "Building Go binary for GOOS=linux")
+		"-trimpath",
+		"-tags", "lambda.norpc",
+		"-o", binaryPath,
+	}, fmt.Sprintf("Building Go binary for GOOS=linux GOARCH=%s", goArch))
 	if err != nil {
 		return err
 	}
 
-	// zip function.zip main
-	return cli.Execute("zip", []string{
-		deploymentFile,
-		"main",
-	}, "Adding Go binary to deployment archive")
+	// The binary must be executable in the archive
+	archive.addFile(goBuildFileName, binaryPath, 0755)
+	return nil
+}
+
+// getCodeSha256 returns the hash of a deployment archive in the
+// same format as the CodeSha256 of a Lambda function
+func getCodeSha256(deploymentFile string) (string, error) {
+	f, err := os.Open(deploymentFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
 }
@@ -0,0 +1,28 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetCodeSha256(t *testing.T) {
+	// Lambda's CodeSha256 is the base64 encoded SHA-256 of the archive
+	contents := []byte("zip contents")
+	archivePath := filepath.Join(t.TempDir(), "deployment.zip")
+	if err := os.WriteFile(archivePath, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(contents)
+	want := base64.StdEncoding.EncodeToString(sum[:])
+
+	got, err := getCodeSha256(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("getCodeSha256() = %s, want %s", got, want)
+	}
+}
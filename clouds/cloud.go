@@ -3,12 +3,38 @@ package clouds
 import (
 	"fmt"
 
+	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/invoke"
+	"github.com/operatorai/kettle-cli/logs"
 	"github.com/operatorai/kettle-cli/settings"
 )
 
 type Service interface {
-	Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) error
+	// Deploy returns nil if nothing has changed since the last deployment
+	Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) (*config.Deployment, error)
+
+	Destroy(directory string, cfg *config.Config, stg *settings.Settings, env string) error
+
+	Logs(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *logs.Options) error
+
+	// Status reports what is deployed, and any mismatches with kettle.json
+	Status(directory string, cfg *config.Config, stg *settings.Settings, env string) (*cli.Report, error)
+
+	// Endpoint returns nil if the service does not have an HTTP endpoint
+	Endpoint(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *invoke.Options) (*invoke.Endpoint, error)
+}
+
+// Rollbacker is implemented by services that keep earlier versions, and that
+// can move traffic back to the version before the live one (or to a given version)
+type Rollbacker interface {
+	Rollback(directory string, cfg *config.Config, stg *settings.Settings, env string, history *config.History, to string) (*config.Deployment, error)
+}
+
+// DirectInvoker is implemented by services that can be invoked
+// without their HTTP endpoint (e.g. aws lambda invoke)
+type DirectInvoker interface {
+	Invoke(directory string, cfg *config.Config, stg *settings.Settings, env string, payload []byte) (*invoke.Response, error)
 }
 
 type Cloud interface {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os/exec"
 
+	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/clouds/gcloud"
 	"github.com/operatorai/kettle-cli/settings"
 )
@@ -23,7 +24,10 @@ func (GoogleCloud) GetService(deploymentType string) (Service, error) {
 func (GoogleCloud) Setup(stg *settings.Settings, overwrite bool) error {
 	_, err := exec.LookPath("gcloud")
 	if err != nil {
-		return fmt.Errorf("please install the gcloud cli: %s", err)
+		return &cli.MissingToolError{
+			Tool: "the gcloud cli",
+			Err:  err,
+		}
 	}
 	if stg.GoogleCloud == nil {
 		stg.GoogleCloud = &settings.GoogleCloudSettings{}
@@ -2,27 +2,35 @@ package gcloud
 
 import (
 	"fmt"
+	"os"
+	"path"
 
-	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
 )
 
-func build(projectID, projectName, deploymentName, env string) error {
-	fmt.Printf("🏭  Building: %s as a Cloud Run container in %s (%s)\n",
-		deploymentName,
-		projectName,
-		env,
-	)
-
-	// Check that the gcloud builds api is enabled
-	// cloudbuild.googleapis.com
+// getBuildFlags returns the flags that set up a Cloud Function's build; Node.js
+// functions that need to be compiled (e.g. TypeScript) run their build script
+// https://cloud.google.com/docs/buildpacks/nodejs#run_custom_build_steps
+func getBuildFlags(cfg *config.Config) []string {
+	if cfg.Config.NodeBuild == nil || cfg.Config.NodeBuild.BuildScript == "" {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("--set-build-env-vars=GOOGLE_NODE_RUN_SCRIPTS=%s", cfg.Config.NodeBuild.BuildScript),
+	}
+}
 
-	containerTag := fmt.Sprintf("gcr.io/%s/%s", projectID, deploymentName)
-	// Build the docker container
-	// gcloud builds submit --tag gcr.io/PROJECT-ID/helloworld
-	return cli.Execute("gcloud", []string{
-		"builds",
-		"submit",
-		"--tag", containerTag,
-		"--project", projectName,
-	}, "Building docker container")
+// getContainerBuildFlag returns the flag that builds a Cloud Run container: from the
+// project's Dockerfile if it has one, or else with Google Cloud's buildpacks, which
+// detect the language (e.g. a package.json for Node.js)
+// https://cloud.google.com/docs/buildpacks/build-application
+func getContainerBuildFlag(directory string, cfg *config.Config, containerTag string) string {
+	if _, err := os.Stat(path.Join(directory, "Dockerfile")); err == nil {
+		return fmt.Sprintf("--tag=%s", containerTag)
+	}
+	pack := fmt.Sprintf("image=%s", containerTag)
+	if cfg.Config.NodeBuild != nil && cfg.Config.NodeBuild.BuildScript != "" {
+		pack += fmt.Sprintf(",env=GOOGLE_NODE_RUN_SCRIPTS=%s", cfg.Config.NodeBuild.BuildScript)
+	}
+	return fmt.Sprintf("--pack=%s", pack)
 }
@@ -3,6 +3,8 @@ package gcloud
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path"
 	"strings"
 
 	"github.com/operatorai/kettle-cli/cli"
@@ -12,13 +14,12 @@ import (
 
 type GoogleCloudRun struct{}
 
-func (GoogleCloudRun) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
+func (GoogleCloudRun) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) (*config.Deployment, error) {
 	environment, err := getEnvironment(stg, env)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// @TODO check if the current build already exists
 	if strings.Contains(cfg.Config.Runtime, "go") {
 		_ = cli.Execute("go", []string{
 			"mod",
@@ -26,22 +27,89 @@ func (GoogleCloudRun) Deploy(directory string, cfg *config.Config, stg *settings
 		}, "Running go mod init")
 	}
 
+	runtimeConfig, err := getRuntimeConfiguration(directory, cfg, env)
+	if err != nil {
+		return nil, err
+	}
+	configurationFlags, envVarsFile, err := runtimeConfig.flags()
+	if err != nil {
+		return nil, err
+	}
+	if envVarsFile != "" {
+		defer os.Remove(envVarsFile)
+	}
+	configurationFlags = append(configurationFlags, runtimeConfig.serviceResourceFlags()...)
+
+	// Skip the build if the source and the deployed image have not changed
+	hash, err := sourceHash(directory)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Config.GoogleCloud.CloudRun == nil {
+		cfg.Config.GoogleCloud.CloudRun = map[string]*config.CloudRunDeployment{}
+	}
+	if previous, ok := cfg.Config.GoogleCloud.CloudRun[env]; ok && !settings.ForceDeploy {
+		if previous.SourceHash == hash {
+			service, err := describeService(cfg, environment)
+			if err == nil && service.deployedImage() == previous.Image {
+				fmt.Printf("⏭  No changes to the code (image: %s); use --force to re-deploy\n", previous.Image)
+				changed := false
+				if !runtimeConfig.matches(service) {
+					if err := updateService(cfg, environment, configurationFlags); err != nil {
+						return nil, err
+					}
+					changed = true
+				}
+
+				// Traffic is moved back to the latest revision after a rollback
+				if !service.servesLatest() {
+					if err := sendTrafficToLatest(cfg, environment); err != nil {
+						return nil, err
+					}
+					changed = true
+				}
+				fmt.Println("🔍  API Endpoint: ", service.Status.URL)
+				cli.GetResult().EndpointURL = service.Status.URL
+				if !changed {
+					return nil, nil
+				}
+				return getCloudRunDeployment(cfg, environment, hash), nil
+			}
+		}
+	}
+
 	fmt.Printf("🏭  Building: %s as a Cloud Run container in %s (%s)\n",
 		cfg.ProjectName,
 		environment.ProjectName,
 		env,
 	)
+
+	// Only upload the files that are not in .kettleignore
+	ignoreFile, err := writeIgnoreFile(directory)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path.Join(directory, ignoreFile))
+
 	containerTag := fmt.Sprintf("gcr.io/%s/%s", environment.ProjectID, cfg.ProjectName)
 	// Build the docker container
 	// gcloud builds submit --tag gcr.io/PROJECT-ID/helloworld
 	err = cli.Execute("gcloud", []string{
 		"builds",
 		"submit",
-		"--tag", containerTag,
+		getContainerBuildFlag(directory, cfg, containerTag),
 		"--project", environment.ProjectID,
+		fmt.Sprintf("--ignore-file=%s", ignoreFile),
 	}, "Building docker container")
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Deploy the image by its digest, so that it can be compared
+	// with the service's image in the next deployment
+	image, err := getImageDigest(containerTag, environment)
+	if err != nil {
+		return nil, err
 	}
 
 	// Deploy the docker container
@@ -51,21 +119,123 @@ func (GoogleCloudRun) Deploy(directory string, cfg *config.Config, stg *settings
 		environment.ProjectName,
 		env,
 	)
-	err = cli.Execute("gcloud", []string{
+	args := []string{
 		"run",
 		"deploy",
 		cfg.ProjectName,
-		"--image", containerTag,
+		"--image", image,
 		"--platform", "managed",
 		"--project", environment.ProjectID,
 		"--allow-unauthenticated",
 		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
-	}, "Deploying Cloud Run container")
+	}
+	err = cli.Execute("gcloud", append(args, configurationFlags...), "Deploying Cloud Run container")
 	if err != nil {
-		return err
+		return nil, err
+	}
+	cfg.Config.GoogleCloud.CloudRun[env] = &config.CloudRunDeployment{
+		SourceHash: hash,
+		Image:      image,
 	}
 
 	// Get the URL
+	service, err := describeService(cfg, environment)
+	if err != nil {
+		cli.Warn("Could not retrieve URL (but the Cloud Run function has deployed)")
+		return &config.Deployment{PackageHash: hash}, nil
+	}
+	fmt.Println("🔍  API Endpoint: ", service.Status.URL)
+	cli.GetResult().EndpointURL = service.Status.URL
+
+	// New revisions do not receive traffic after a rollback, until it is moved back
+	if !service.servesLatest() {
+		if err := sendTrafficToLatest(cfg, environment); err != nil {
+			return nil, err
+		}
+	}
+	return &config.Deployment{
+		PackageHash:      hash,
+		CloudRunRevision: service.Status.LatestReadyRevisionName,
+	}, nil
+}
+
+type cloudRunService struct {
+	Spec struct {
+		Template struct {
+			Metadata struct {
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+			Spec struct {
+				ContainerConcurrency int `json:"containerConcurrency"`
+				TimeoutSeconds       int `json:"timeoutSeconds"`
+				Containers           []struct {
+					Image     string `json:"image"`
+					Resources struct {
+						Limits struct {
+							CPU    string `json:"cpu"`
+							Memory string `json:"memory"`
+						} `json:"limits"`
+					} `json:"resources"`
+					Env []struct {
+						Name      string `json:"name"`
+						Value     string `json:"value"`
+						ValueFrom struct {
+							SecretKeyRef struct {
+								Name string `json:"name"`
+								Key  string `json:"key"`
+							} `json:"secretKeyRef"`
+						} `json:"valueFrom"`
+					} `json:"env"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+	Status struct {
+		URL                       string `json:"url"`
+		LatestCreatedRevisionName string `json:"latestCreatedRevisionName"`
+		LatestReadyRevisionName   string `json:"latestReadyRevisionName"`
+		Traffic                   []struct {
+			RevisionName   string `json:"revisionName"`
+			Percent        int    `json:"percent"`
+			LatestRevision bool   `json:"latestRevision"`
+		} `json:"traffic"`
+		Conditions []struct {
+			Type               string `json:"type"`
+			Status             string `json:"status"`
+			LastTransitionTime string `json:"lastTransitionTime"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+func (s *cloudRunService) deployedImage() string {
+	if len(s.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	return s.Spec.Template.Spec.Containers[0].Image
+}
+
+// servesLatest returns true if all of the traffic goes to the latest revision
+func (s *cloudRunService) servesLatest() bool {
+	for _, target := range s.Status.Traffic {
+		if target.LatestRevision && target.Percent == 100 {
+			return true
+		}
+	}
+	return false
+}
+
+// liveRevision returns the revision that receives the most traffic
+func (s *cloudRunService) liveRevision() string {
+	revision, percent := s.Status.LatestReadyRevisionName, -1
+	for _, target := range s.Status.Traffic {
+		if target.Percent > percent && target.RevisionName != "" {
+			revision, percent = target.RevisionName, target.Percent
+		}
+	}
+	return revision
+}
+
+func describeService(cfg *config.Config, environment *settings.GoogleCloudProject) (*cloudRunService, error) {
 	output, err := cli.ExecuteWithResult("gcloud", []string{
 		"run",
 		"services",
@@ -74,22 +244,82 @@ func (GoogleCloudRun) Deploy(directory string, cfg *config.Config, stg *settings
 		"--project", environment.ProjectID,
 		"--region", environment.DeploymentRegion,
 		"--format", "json",
-	}, "Querying for Cloud Run URL")
+	}, "Querying for Cloud Run service")
 	if err != nil {
-		fmt.Println("😥  Could not retrieve URL (but the Cloud Run function has deployed)")
-		return nil
+		return nil, err
 	}
 
-	var results struct {
-		Status struct {
-			URL string `json:"url"`
-		} `json:"status"`
+	service := &cloudRunService{}
+	if err := json.Unmarshal(output, service); err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal(output, &results); err != nil {
-		fmt.Println("😥  Could not parse response (but the Cloud Run function has deployed)")
-		return nil
+	return service, nil
+}
+
+// getCloudRunDeployment returns the service's latest ready revision
+func getCloudRunDeployment(cfg *config.Config, environment *settings.GoogleCloudProject, hash string) *config.Deployment {
+	deployment := &config.Deployment{PackageHash: hash}
+	if service, err := describeService(cfg, environment); err == nil {
+		deployment.CloudRunRevision = service.Status.LatestReadyRevisionName
 	}
+	return deployment
+}
 
-	fmt.Println("🔍  API Endpoint: ", results.Status.URL)
-	return nil
+// sendTrafficToLatest moves all of a service's traffic to its latest revision
+// https://cloud.google.com/sdk/gcloud/reference/run/services/update-traffic
+func sendTrafficToLatest(cfg *config.Config, environment *settings.GoogleCloudProject) error {
+	return cli.Execute("gcloud", []string{
+		"run",
+		"services",
+		"update-traffic", cfg.ProjectName,
+		"--to-latest",
+		"--platform", "managed",
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+	}, "Sending traffic to the latest revision")
+}
+
+// updateService replaces the environment variables, secrets & resources of a service
+func updateService(cfg *config.Config, environment *settings.GoogleCloudProject, configurationFlags []string) error {
+	args := []string{
+		"run",
+		"services",
+		"update", cfg.ProjectName,
+		"--platform", "managed",
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+	}
+	return cli.Execute("gcloud", append(args, configurationFlags...), "Updating Cloud Run service configuration")
+}
+
+// getImageDigest returns the image reference with its digest,
+// e.g. gcr.io/PROJECT-ID/helloworld@sha256:...; if the digest
+// cannot be found, the image is deployed by its tag
+func getImageDigest(containerTag string, environment *settings.GoogleCloudProject) (string, error) {
+	output, err := cli.ExecuteWithResult("gcloud", []string{
+		"container",
+		"images",
+		"describe", containerTag,
+		"--project", environment.ProjectID,
+		"--format", "json",
+	}, "Querying for the container image digest")
+	if err != nil {
+		if settings.DebugMode {
+			fmt.Println(err.Error())
+		}
+		return containerTag, nil
+	}
+
+	var result struct {
+		ImageSummary struct {
+			FullyQualifiedDigest string `json:"fully_qualified_digest"`
+		} `json:"image_summary"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return "", err
+	}
+	if result.ImageSummary.FullyQualifiedDigest == "" {
+		return containerTag, nil
+	}
+	return result.ImageSummary.FullyQualifiedDigest, nil
 }
@@ -0,0 +1,212 @@
+package gcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+const (
+	minScaleAnnotation = "autoscaling.knative.dev/minScale"
+	maxScaleAnnotation = "autoscaling.knative.dev/maxScale"
+)
+
+// runtimeConfiguration is the environment & resources of a function or service;
+// secrets map variable names to <secret>:<version> in Secret Manager. The
+// environment is only managed if kettle.json has an environment section
+type runtimeConfiguration struct {
+	managed   bool
+	variables map[string]string
+	secrets   map[string]string
+	resources *config.Resources
+}
+
+func getRuntimeConfiguration(directory string, cfg *config.Config, env string) (*runtimeConfiguration, error) {
+	resolved, err := cfg.ResolveEnvironment(directory, env)
+	if err != nil {
+		return nil, cli.NewConfigError(err)
+	}
+
+	secrets := map[string]string{}
+	for key, reference := range resolved.Secrets {
+		parts := strings.Split(reference, ":")
+		switch {
+		case len(parts) == 1 && parts[0] != "":
+			secrets[key] = fmt.Sprintf("%s:latest", parts[0])
+		case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+			secrets[key] = reference
+		default:
+			return nil, cli.NewConfigError(fmt.Errorf("invalid secret: %s (use <secret>:<version>)", reference))
+		}
+	}
+
+	resources, err := cfg.ResolveResources(env)
+	if err != nil {
+		return nil, cli.NewConfigError(err)
+	}
+	return &runtimeConfiguration{
+		managed:   cfg.Config.Environment != nil,
+		variables: resolved.Variables,
+		secrets:   secrets,
+		resources: resources,
+	}, nil
+}
+
+// functionResourceFlags returns the flags that set the resources of a Cloud Function,
+// which handles one request per instance and has its CPU set by its memory
+func (r *runtimeConfiguration) functionResourceFlags() ([]string, error) {
+	switch {
+	case r.resources.CPU != "":
+		return nil, cli.NewConfigError(fmt.Errorf("cpu is not supported for Google Cloud Functions (set memory_mb instead)"))
+	case r.resources.Concurrency != 0:
+		return nil, cli.NewConfigError(fmt.Errorf("concurrency is not supported for Google Cloud Functions"))
+	}
+
+	flags := r.scalingFlags()
+	if r.resources.MemoryMB != 0 {
+		flags = append(flags, fmt.Sprintf("--memory=%dMB", r.resources.MemoryMB))
+	}
+	return flags, nil
+}
+
+// serviceResourceFlags returns the flags that set the resources of a Cloud Run service
+func (r *runtimeConfiguration) serviceResourceFlags() []string {
+	flags := r.scalingFlags()
+	if r.resources.MemoryMB != 0 {
+		flags = append(flags, fmt.Sprintf("--memory=%s", r.serviceMemory()))
+	}
+	if r.resources.CPU != "" {
+		flags = append(flags, fmt.Sprintf("--cpu=%s", r.resources.CPU))
+	}
+	if r.resources.Concurrency != 0 {
+		flags = append(flags, fmt.Sprintf("--concurrency=%d", r.resources.Concurrency))
+	}
+	return flags
+}
+
+func (r *runtimeConfiguration) scalingFlags() []string {
+	flags := []string{}
+	if r.resources.TimeoutSeconds != 0 {
+		flags = append(flags, fmt.Sprintf("--timeout=%ds", r.resources.TimeoutSeconds))
+	}
+	if r.resources.MinInstances != nil {
+		flags = append(flags, fmt.Sprintf("--min-instances=%d", *r.resources.MinInstances))
+	}
+	if r.resources.MaxInstances != nil {
+		flags = append(flags, fmt.Sprintf("--max-instances=%d", *r.resources.MaxInstances))
+	}
+	return flags
+}
+
+func (r *runtimeConfiguration) serviceMemory() string {
+	return fmt.Sprintf("%dMi", r.resources.MemoryMB)
+}
+
+// flags returns the gcloud flags that replace the environment variables & secrets of a
+// function or service, and the path of the env vars file that the caller should remove;
+// there are none if the environment is not managed
+func (r *runtimeConfiguration) flags() ([]string, string, error) {
+	if !r.managed {
+		return nil, "", nil
+	}
+
+	var flags []string
+	var envVarsFile string
+	if len(r.variables) > 0 {
+		// The values are written to a file, so that they are not visible in the arguments
+		// of the gcloud cli; JSON is valid YAML
+		data, err := json.Marshal(r.variables)
+		if err != nil {
+			return nil, "", err
+		}
+		f, err := ioutil.TempFile("", "kettle-env-vars*.yaml")
+		if err != nil {
+			return nil, "", err
+		}
+		defer f.Close()
+		if _, err := f.Write(data); err != nil {
+			os.Remove(f.Name())
+			return nil, "", err
+		}
+		envVarsFile = f.Name()
+		flags = append(flags, fmt.Sprintf("--env-vars-file=%s", envVarsFile))
+	} else {
+		flags = append(flags, "--clear-env-vars")
+	}
+
+	if len(r.secrets) > 0 {
+		keys := make([]string, 0, len(r.secrets))
+		for key := range r.secrets {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, fmt.Sprintf("%s=%s", key, r.secrets[key]))
+		}
+		flags = append(flags, fmt.Sprintf("--set-secrets=%s", strings.Join(values, ",")))
+	} else {
+		flags = append(flags, "--clear-secrets")
+	}
+	return flags, envVarsFile, nil
+}
+
+// matches returns true if a Cloud Run service's environment and resources
+// are the same; resources (and environments) that are not set in kettle.json
+// are not compared
+func (r *runtimeConfiguration) matches(service *cloudRunService) bool {
+	if len(service.Spec.Template.Spec.Containers) == 0 {
+		return false
+	}
+	template := service.Spec.Template
+	container := template.Spec.Containers[0]
+
+	resources := r.resources
+	switch {
+	case resources.MemoryMB != 0 && container.Resources.Limits.Memory != r.serviceMemory():
+		return false
+	case resources.CPU != "" && container.Resources.Limits.CPU != resources.CPU:
+		return false
+	case resources.Concurrency != 0 && template.Spec.ContainerConcurrency != resources.Concurrency:
+		return false
+	case resources.TimeoutSeconds != 0 && template.Spec.TimeoutSeconds != resources.TimeoutSeconds:
+		return false
+	case resources.MinInstances != nil && template.Metadata.Annotations[minScaleAnnotation] != strconv.Itoa(*resources.MinInstances):
+		return false
+	case resources.MaxInstances != nil && template.Metadata.Annotations[maxScaleAnnotation] != strconv.Itoa(*resources.MaxInstances):
+		return false
+	}
+	if !r.managed {
+		return true
+	}
+
+	variables := map[string]string{}
+	secrets := map[string]string{}
+	for _, env := range container.Env {
+		if ref := env.ValueFrom.SecretKeyRef; ref.Name != "" {
+			secrets[env.Name] = fmt.Sprintf("%s:%s", ref.Name, ref.Key)
+			continue
+		}
+		variables[env.Name] = env.Value
+	}
+	return mapsEqual(variables, r.variables) && mapsEqual(secrets, r.secrets)
+}
+
+func mapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
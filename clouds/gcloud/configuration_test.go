@@ -0,0 +1,106 @@
+package gcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/config"
+)
+
+func TestRuntimeConfigurationFlags(t *testing.T) {
+	cfg := &config.Config{}
+	runtimeConfig, err := getRuntimeConfiguration(t.TempDir(), cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without an environment in kettle.json, the existing variables & secrets are left as they are
+	flags, envVarsFile, err := runtimeConfig.flags()
+	if err != nil || len(flags) != 0 || envVarsFile != "" {
+		t.Errorf("flags() without an environment = %v, %q, %v; want none", flags, envVarsFile, err)
+	}
+	if !runtimeConfig.matches(serviceWithEnv(t, "SET", "in the console")) {
+		t.Error("matches() compared an environment that is not in kettle.json")
+	}
+
+	// An empty environment clears them
+	cfg.Config.Environment = &config.Environment{}
+	runtimeConfig, err = getRuntimeConfiguration(t.TempDir(), cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags, _, err = runtimeConfig.flags()
+	if want := []string{"--clear-env-vars", "--clear-secrets"}; err != nil || !reflect.DeepEqual(flags, want) {
+		t.Errorf("flags() with an empty environment = %v, %v; want %v", flags, err, want)
+	}
+	if runtimeConfig.matches(serviceWithEnv(t, "SET", "in the console")) {
+		t.Error("matches() = true for a service with a variable that is not in kettle.json")
+	}
+
+	cfg.Config.Environment = &config.Environment{
+		Variables: map[string]string{"GREETING": "hello"},
+		Secrets:   map[string]string{"TOKEN": "token"},
+	}
+	runtimeConfig, err = getRuntimeConfiguration(t.TempDir(), cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flags, envVarsFile, err = runtimeConfig.flags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(envVarsFile)
+	if len(flags) != 2 || !strings.HasPrefix(flags[0], "--env-vars-file=") || flags[1] != "--set-secrets=TOKEN=token:latest" {
+		t.Errorf("flags() = %v", flags)
+	}
+}
+
+// serviceWithEnv returns a Cloud Run service that has one environment variable
+func serviceWithEnv(t *testing.T, name, value string) *cloudRunService {
+	service := &cloudRunService{}
+	data := fmt.Sprintf(`{"spec": {"template": {"spec": {"containers": [{"env": [{"name": %q, "value": %q}]}]}}}}`, name, value)
+	if err := json.Unmarshal([]byte(data), service); err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+func TestResourceFlags(t *testing.T) {
+	zero, five := 0, 5
+	cfg := &config.Config{}
+	cfg.Config.Resources = &config.Resources{
+		MemoryMB:       512,
+		CPU:            "2",
+		Concurrency:    40,
+		TimeoutSeconds: 60,
+		MinInstances:   &zero,
+		MaxInstances:   &five,
+	}
+	runtimeConfig, err := getRuntimeConfiguration(t.TempDir(), cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--timeout=60s", "--min-instances=0", "--max-instances=5", "--memory=512Mi", "--cpu=2", "--concurrency=40"}
+	if got := runtimeConfig.serviceResourceFlags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("serviceResourceFlags() = %v, want %v", got, want)
+	}
+
+	// Cloud Functions set their CPU by their memory, and handle one request at a time
+	if _, err := runtimeConfig.functionResourceFlags(); err == nil {
+		t.Error("functionResourceFlags() with a cpu did not return an error")
+	}
+	cfg.Config.Resources.CPU = ""
+	cfg.Config.Resources.Concurrency = 0
+	runtimeConfig, err = getRuntimeConfiguration(t.TempDir(), cfg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"--timeout=60s", "--min-instances=0", "--max-instances=5", "--memory=512MB"}
+	if got, err := runtimeConfig.functionResourceFlags(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("functionResourceFlags() = %v, %v; want %v", got, err, want)
+	}
+}
@@ -0,0 +1,72 @@
+package gcloud
+
+import (
+	"fmt"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// https://cloud.google.com/sdk/gcloud/reference/functions/delete
+func (GoogleCloudFunction) Destroy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧨  Destroying: %s (Google Cloud function) in %s (%s)\n",
+		cfg.ProjectName,
+		environment.ProjectName,
+		env,
+	)
+	return cli.Execute("gcloud", []string{
+		"functions",
+		"delete",
+		cfg.ProjectName,
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+		"--quiet",
+	}, "Deleting Cloud Function")
+}
+
+// https://cloud.google.com/sdk/gcloud/reference/run/services/delete
+func (GoogleCloudRun) Destroy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🧨  Destroying: %s (Cloud Run container) in %s (%s)\n",
+		cfg.ProjectName,
+		environment.ProjectName,
+		env,
+	)
+	err = cli.Execute("gcloud", []string{
+		"run",
+		"services",
+		"delete",
+		cfg.ProjectName,
+		"--platform", "managed",
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+		"--quiet",
+	}, "Deleting Cloud Run service")
+	if err != nil {
+		return err
+	}
+	// The environment's last deployment is removed from kettle.json, so that
+	// the next deployment is not skipped as unchanged
+	delete(cfg.Config.GoogleCloud.CloudRun, env)
+
+	// Delete the container image that was built for the service
+	containerTag := fmt.Sprintf("gcr.io/%s/%s", environment.ProjectID, cfg.ProjectName)
+	return cli.Execute("gcloud", []string{
+		"container",
+		"images",
+		"delete",
+		containerTag,
+		"--force-delete-tags",
+		"--quiet",
+	}, "Deleting Cloud Run container image")
+}
@@ -0,0 +1,46 @@
+package gcloud
+
+import (
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+func TestDestroyCloudRun(t *testing.T) {
+	stg := &settings.Settings{
+		GoogleCloud: &settings.GoogleCloudSettings{
+			Environments: map[string]*settings.GoogleCloudProject{
+				"dev": {ProjectID: "hello-dev", DeploymentRegion: "europe-west1"},
+			},
+		},
+	}
+	cfg := &config.Config{ProjectName: "hello"}
+	cfg.Config.GoogleCloud.CloudRun = map[string]*config.CloudRunDeployment{
+		"dev":  {SourceHash: "abc", Image: "gcr.io/hello-dev/hello:abc"},
+		"prod": {SourceHash: "def", Image: "gcr.io/hello-prod/hello:def"},
+	}
+
+	fake := cli.NewFakeRunner()
+	fake.Expect("gcloud", "run", "services", "delete", "hello", "--platform", "managed", "--project", "hello-dev", "--region=europe-west1", "--quiet")
+	fake.Expect("gcloud", "container", "images", "delete", "gcr.io/hello-dev/hello", "--force-delete-tags", "--quiet")
+	previous := cli.GetRunner()
+	cli.SetRunner(fake)
+	defer cli.SetRunner(previous)
+
+	if err := (GoogleCloudRun{}).Destroy(t.TempDir(), cfg, stg, "dev"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := fake.Remaining(); len(remaining) != 0 {
+		t.Errorf("%d expected commands were not run", len(remaining))
+	}
+
+	// Only the destroyed environment's deployment is removed from kettle.json
+	if _, ok := cfg.Config.GoogleCloud.CloudRun["dev"]; ok {
+		t.Errorf("the dev deployment is still in kettle.json")
+	}
+	if _, ok := cfg.Config.GoogleCloud.CloudRun["prod"]; !ok {
+		t.Errorf("the prod deployment was removed from kettle.json")
+	}
+}
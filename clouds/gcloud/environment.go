@@ -3,20 +3,16 @@ package gcloud
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/settings"
 )
 
 func SetProjects(sts *settings.GoogleCloudSettings, overwrite bool) error {
-	environments := map[string]*settings.GoogleCloudProject{
-		"development": sts.DevProject,
-		"production":  sts.ProdProject,
-	}
-
 	if !overwrite {
-		valuesSet := true
-		for _, environment := range environments {
+		valuesSet := len(sts.Environments) > 0
+		for _, environment := range sts.Environments {
 			if environment == nil {
 				valuesSet = false
 				break
@@ -31,26 +27,43 @@ func SetProjects(sts *settings.GoogleCloudSettings, overwrite bool) error {
 		}
 	}
 
-	projects, err := getGoogleCloudProjects()
+	// Environments are added or removed by listing their names
+	defaultNames := strings.Join(sts.EnvironmentNames(), ",")
+	if defaultNames == "" {
+		defaultNames = "dev,prod"
+	}
+	value, err := cli.PromptForStringWithDefault("gcloud.environments",
+		"Environments (comma-separated, e.g. dev,staging,prod)", defaultNames)
 	if err != nil {
 		return err
 	}
-
-	regions, err := getGoogleCloudRegions()
+	names, err := settings.ParseEnvironmentNames(value)
 	if err != nil {
-		return err
+		return &cli.UserInputError{Err: err}
+	}
+	if len(names) == 0 {
+		return cli.NewUserInputError("please specify at least one environment")
 	}
 
-	sts.ProdProject, err = setupEnvironment("production", projects, regions)
+	projects, err := getGoogleCloudProjects()
 	if err != nil {
 		return err
 	}
 
-	sts.DevProject, err = setupEnvironment("development", projects, regions)
+	regions, err := getGoogleCloudRegions()
 	if err != nil {
 		return err
 	}
 
+	environments := map[string]*settings.GoogleCloudProject{}
+	for _, name := range names {
+		environments[name], err = setupEnvironment(name, projects, regions)
+		if err != nil {
+			return err
+		}
+	}
+	sts.Environments = environments
+
 	// @TODO: consider enabling APIs that may be required
 	// e.g. (Cloud Run, Cloud Functions) or at least checking whether they
 	// are enabled with:
@@ -62,13 +75,13 @@ func SetProjects(sts *settings.GoogleCloudSettings, overwrite bool) error {
 func setupEnvironment(name string, projects, regions map[string]string) (*settings.GoogleCloudProject, error) {
 	fmt.Printf("\n🔎 Set up a Google Cloud environment: %s\n", name)
 	prompt := fmt.Sprintf("Select your project for \"%s\"", name)
-	projectName, projectID, err := cli.PromptForKeyValue(prompt, projects)
+	projectName, projectID, err := cli.PromptForKeyValue(fmt.Sprintf("gcloud.%s.project", name), prompt, projects)
 	if err != nil {
 		return nil, err
 	}
 
 	prompt = fmt.Sprintf("Select your deployment region for \"%s\"", name)
-	region, err := cli.PromptForValue(prompt, regions, false)
+	region, err := cli.PromptForValue(fmt.Sprintf("gcloud.%s.region", name), prompt, regions, false)
 	if err != nil {
 		return nil, err
 	}
@@ -143,11 +156,13 @@ func getGoogleCloudRegions() (map[string]string, error) {
 }
 
 func getEnvironment(stg *settings.Settings, env string) (*settings.GoogleCloudProject, error) {
+	configured := strings.Join(stg.GoogleCloud.EnvironmentNames(), ", ")
 	if env == "" {
-		return nil, fmt.Errorf("please specify --env=<value> (prod/dev)")
+		return nil, cli.NewUserInputError("please specify --env=<value> (%s)", configured)
 	}
-	if env == "prod" || env == "production" {
-		return stg.GoogleCloud.ProdProject, nil
+	environment, ok := stg.GoogleCloud.Environments[env]
+	if !ok || environment == nil {
+		return nil, cli.NewUserInputError("unknown environment: %s (configured: %s); run kettle init to add it", env, configured)
 	}
-	return stg.GoogleCloud.DevProject, nil
+	return environment, nil
 }
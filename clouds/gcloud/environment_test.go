@@ -0,0 +1,40 @@
+package gcloud
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+func TestGetEnvironment(t *testing.T) {
+	stg := &settings.Settings{
+		GoogleCloud: &settings.GoogleCloudSettings{
+			Environments: map[string]*settings.GoogleCloudProject{
+				"dev":     {ProjectID: "hello-dev"},
+				"staging": {ProjectID: "hello-staging"},
+			},
+		},
+	}
+
+	environment, err := getEnvironment(stg, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if environment.ProjectID != "hello-staging" {
+		t.Errorf("getEnvironment(staging) = %s, want hello-staging", environment.ProjectID)
+	}
+
+	for _, env := range []string{"", "prod"} {
+		_, err := getEnvironment(stg, env)
+		var inputErr *cli.UserInputError
+		if !errors.As(err, &inputErr) {
+			t.Fatalf("getEnvironment(%q): err = %v, want a UserInputError", env, err)
+		}
+		if !strings.Contains(err.Error(), "dev, staging") {
+			t.Errorf("getEnvironment(%q): %v, want the configured environments", env, err)
+		}
+	}
+}
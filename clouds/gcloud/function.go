@@ -2,6 +2,8 @@ package gcloud
 
 import (
 	"fmt"
+	"os"
+	"path"
 
 	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/config"
@@ -11,10 +13,10 @@ import (
 type GoogleCloudFunction struct{}
 
 // https://cloud.google.com/sdk/gcloud/reference/functions/deploy
-func (GoogleCloudFunction) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) error {
+func (GoogleCloudFunction) Deploy(directory string, cfg *config.Config, stg *settings.Settings, env string) (*config.Deployment, error) {
 	environment, err := getEnvironment(stg, env)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	fmt.Printf("🚢  Deploying %s as a Google Cloud function to %s (%s)\n",
@@ -23,13 +25,39 @@ func (GoogleCloudFunction) Deploy(directory string, cfg *config.Config, stg *set
 		env,
 	)
 	fmt.Printf("⏭  Entry point: %s (%s)\n", cfg.Config.EntryFunction, cfg.Config.Runtime)
-	fmt.Printf("🔍  https://%s-%s.cloudfunctions.net/%s\n",
+	endpointURL := fmt.Sprintf("https://%s-%s.cloudfunctions.net/%s",
 		environment.DeploymentRegion,
 		environment.ProjectID,
 		cfg.ProjectName,
 	)
+	fmt.Printf("🔍  %s\n", endpointURL)
+	cli.GetResult().EndpointURL = endpointURL
 
-	return cli.Execute("gcloud", []string{
+	// Only upload the files that are not in .kettleignore
+	ignoreFile, err := writeIgnoreFile(directory)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path.Join(directory, ignoreFile))
+
+	// Set the function's environment variables, secrets & resources
+	runtimeConfig, err := getRuntimeConfiguration(directory, cfg, env)
+	if err != nil {
+		return nil, err
+	}
+	environmentFlags, envVarsFile, err := runtimeConfig.flags()
+	if err != nil {
+		return nil, err
+	}
+	if envVarsFile != "" {
+		defer os.Remove(envVarsFile)
+	}
+	resourceFlags, err := runtimeConfig.functionResourceFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
 		"functions",
 		"deploy",
 		cfg.ProjectName,
@@ -38,6 +66,19 @@ func (GoogleCloudFunction) Deploy(directory string, cfg *config.Config, stg *set
 		"--trigger-http",
 		fmt.Sprintf("--entry-point=%s", cfg.Config.EntryFunction),
 		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+		fmt.Sprintf("--ignore-file=%s", ignoreFile),
 		"--allow-unauthenticated",
-	}, "Deploying Cloud Function")
+	}
+	args = append(args, getBuildFlags(cfg)...)
+	err = cli.Execute("gcloud", append(append(args, environmentFlags...), resourceFlags...), "Deploying Cloud Function")
+	if err != nil {
+		return nil, err
+	}
+
+	// Cloud Functions do not keep earlier versions to roll back to
+	hash, err := sourceHash(directory)
+	if err != nil {
+		return nil, err
+	}
+	return &config.Deployment{PackageHash: hash}, nil
 }
@@ -0,0 +1,15 @@
+package gcloud
+
+import (
+	"github.com/operatorai/kettle-cli/ignore"
+)
+
+// sourceHash returns a hash of the files that are uploaded to Google
+// Cloud (i.e. that are not in .kettleignore or .gcloudignore)
+func sourceHash(directory string) (string, error) {
+	lines, err := getIgnoreLines(directory)
+	if err != nil {
+		return "", err
+	}
+	return ignore.Hash(directory, ignore.New(lines))
+}
@@ -0,0 +1,63 @@
+package gcloud
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(directory string) error
+		changed bool
+	}{
+		{"no change", func(string) error { return nil }, false},
+		{"changed file", func(directory string) error {
+			return os.WriteFile(filepath.Join(directory, "main.py"), []byte("changed"), 0644)
+		}, true},
+		{"renamed file", func(directory string) error {
+			return os.Rename(filepath.Join(directory, "main.py"), filepath.Join(directory, "app.py"))
+		}, true},
+		{"file in .kettleignore", func(directory string) error {
+			return os.WriteFile(filepath.Join(directory, "notes.md"), []byte("x"), 0644)
+		}, false},
+		{"file in .gcloudignore", func(directory string) error {
+			return os.WriteFile(filepath.Join(directory, "data.csv"), []byte("x"), 0644)
+		}, false},
+		{"temporary ignore file", func(directory string) error {
+			_, err := writeIgnoreFile(directory)
+			return err
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+			files := map[string]string{
+				"main.py":       "def handler(request): pass",
+				".kettleignore": "*.md\n",
+				".gcloudignore": "*.csv\n",
+			}
+			for name, contents := range files {
+				if err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			before, err := sourceHash(directory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := test.change(directory); err != nil {
+				t.Fatal(err)
+			}
+			after, err := sourceHash(directory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed := before != after; changed != test.changed {
+				t.Errorf("hash changed = %t, want %t", changed, test.changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package gcloud
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/ignore"
+)
+
+const (
+	gcloudIgnoreFileName = ".gcloudignore"
+	ignoreFilePattern    = ".kettleignore-gcloud*"
+)
+
+// getIgnoreLines returns the patterns of the files that are not uploaded: those in
+// .kettleignore (and the defaults), any existing .gcloudignore, and the temporary
+// ignore file itself. They are used both to upload and to hash the files
+func getIgnoreLines(directory string) ([]string, error) {
+	lines, err := ignore.Lines(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	gcloudIgnore, err := os.ReadFile(path.Join(directory, gcloudIgnoreFileName))
+	if err == nil {
+		lines = append(lines, strings.Split(string(gcloudIgnore), "\n")...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return append(lines, ignoreFilePattern), nil
+}
+
+// writeIgnoreFile creates a temporary ignore file in the project directory
+// with the getIgnoreLines patterns, to be used with --ignore-file. It
+// returns the file's name (relative to the directory)
+func writeIgnoreFile(directory string) (string, error) {
+	lines, err := getIgnoreLines(directory)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(directory, ignoreFilePattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	_, fileName := path.Split(f.Name())
+	return fileName, nil
+}
@@ -0,0 +1,65 @@
+package gcloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/invoke"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Endpoint returns the function's HTTP trigger URL
+func (GoogleCloudFunction) Endpoint(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *invoke.Options) (*invoke.Endpoint, error) {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	return getEndpoint(fmt.Sprintf("https://%s-%s.cloudfunctions.net/%s",
+		environment.DeploymentRegion,
+		environment.ProjectID,
+		cfg.ProjectName,
+	), opts)
+}
+
+// Endpoint returns the service's URL
+func (GoogleCloudRun) Endpoint(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *invoke.Options) (*invoke.Endpoint, error) {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	service, err := describeService(cfg, environment)
+	if err != nil {
+		return nil, err
+	}
+	if service.Status.URL == "" {
+		return nil, nil
+	}
+	return getEndpoint(service.Status.URL, opts)
+}
+
+// getEndpoint adds an identity token to the endpoint's headers, if it has been asked for
+// https://cloud.google.com/run/docs/authenticating/developers
+func getEndpoint(url string, opts *invoke.Options) (*invoke.Endpoint, error) {
+	if opts.APIKey != "" {
+		return nil, cli.NewUserInputError("API keys are only supported for AWS")
+	}
+
+	endpoint := &invoke.Endpoint{
+		URL:     url,
+		Headers: map[string]string{},
+	}
+	if opts.IdentityToken {
+		// The token is not recorded in sessions
+		output, err := cli.ExecuteSecret("gcloud", []string{
+			"auth",
+			"print-identity-token",
+		}, "Getting an identity token")
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Headers["Authorization"] = fmt.Sprintf("Bearer %s", strings.TrimSpace(string(output)))
+	}
+	return endpoint, nil
+}
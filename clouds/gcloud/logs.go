@@ -0,0 +1,100 @@
+package gcloud
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/logs"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Logs reads the function's logs; filters and --follow use Cloud Logging
+// https://cloud.google.com/sdk/gcloud/reference/functions/logs/read
+func (GoogleCloudFunction) Logs(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *logs.Options) error {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return err
+	}
+	if !opts.JSON {
+		fmt.Printf("📜  Logs: %s (Google Cloud function in %s)\n", cfg.ProjectName, environment.ProjectName)
+	}
+
+	if opts.Follow || opts.Filter != "" {
+		resourceFilter := fmt.Sprintf(`resource.type="cloud_function" AND resource.labels.function_name="%s"`, cfg.ProjectName)
+		return readLogs(resourceFilter, environment, opts)
+	}
+
+	since, err := opts.SinceDuration()
+	if err != nil {
+		return cli.NewUserInputError(err.Error())
+	}
+	args := []string{
+		"functions",
+		"logs",
+		"read",
+		cfg.ProjectName,
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+		fmt.Sprintf("--start-time=%s", time.Now().Add(-since).UTC().Format(time.RFC3339)),
+	}
+	if opts.JSON {
+		args = append(args, "--format", "json")
+	}
+	return cli.Stream("gcloud", args, "Reading logs")
+}
+
+// Logs reads the service's logs from Cloud Logging
+// https://cloud.google.com/sdk/gcloud/reference/logging/read
+func (GoogleCloudRun) Logs(directory string, cfg *config.Config, stg *settings.Settings, env string, opts *logs.Options) error {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return err
+	}
+	if !opts.JSON {
+		fmt.Printf("📜  Logs: %s (Cloud Run service in %s)\n", cfg.ProjectName, environment.ProjectName)
+	}
+
+	resourceFilter := fmt.Sprintf(`resource.type="cloud_run_revision" AND resource.labels.service_name="%s"`, cfg.ProjectName)
+	return readLogs(resourceFilter, environment, opts)
+}
+
+// readLogs reads or tails the log entries that match a filter; tailing
+// requires the gcloud beta component and starts from the current time
+func readLogs(resourceFilter string, environment *settings.GoogleCloudProject, opts *logs.Options) error {
+	filters := []string{resourceFilter}
+	if opts.Filter != "" {
+		filters = append(filters, fmt.Sprintf("(%s)", opts.Filter))
+	}
+	format := "value(timestamp,severity,textPayload)"
+	if opts.JSON {
+		format = "json"
+	}
+
+	if opts.Follow {
+		return cli.Stream("gcloud", []string{
+			"beta",
+			"logging",
+			"tail",
+			strings.Join(filters, " AND "),
+			"--project", environment.ProjectID,
+			"--format", format,
+		}, "Tailing logs")
+	}
+
+	since, err := opts.SinceDuration()
+	if err != nil {
+		return cli.NewUserInputError(err.Error())
+	}
+	return cli.Stream("gcloud", []string{
+		"logging",
+		"read",
+		strings.Join(filters, " AND "),
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--freshness=%ds", int(since.Seconds())),
+		"--order", "asc",
+		"--format", format,
+	}, "Reading logs")
+}
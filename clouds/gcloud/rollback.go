@@ -0,0 +1,62 @@
+package gcloud
+
+import (
+	"fmt"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// Rollback sends all of the service's traffic to the revision that was deployed
+// before the live one, or to the given revision
+func (GoogleCloudRun) Rollback(directory string, cfg *config.Config, stg *settings.Settings, env string, history *config.History, to string) (*config.Deployment, error) {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+	service, err := describeService(cfg, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	live := service.liveRevision()
+	revision := to
+	if revision == "" {
+		previous := history.Previous(env, live, getDeploymentRevision)
+		if previous == nil {
+			return nil, cli.NewUserInputError("there is no earlier revision of %s to roll back to", cfg.ProjectName)
+		}
+		revision = previous.CloudRunRevision
+	}
+	if revision == live {
+		return nil, cli.NewUserInputError("revision %s of %s is already live", revision, cfg.ProjectName)
+	}
+
+	fmt.Printf("⏪  Rolling back: %s from revision %s to %s\n", cfg.ProjectName, live, revision)
+	err = cli.Execute("gcloud", []string{
+		"run",
+		"services",
+		"update-traffic", cfg.ProjectName,
+		fmt.Sprintf("--to-revisions=%s=100", revision),
+		"--platform", "managed",
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+	}, fmt.Sprintf("Sending traffic to revision %s", revision))
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := &config.Deployment{
+		CloudRunRevision: revision,
+	}
+	if previous := history.Find(env, revision, getDeploymentRevision); previous != nil {
+		deployment.GitSHA = previous.GitSHA
+		deployment.PackageHash = previous.PackageHash
+	}
+	return deployment, nil
+}
+
+func getDeploymentRevision(deployment *config.Deployment) string {
+	return deployment.CloudRunRevision
+}
@@ -0,0 +1,147 @@
+package gcloud
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+type cloudFunction struct {
+	Status            string `json:"status"`
+	Runtime           string `json:"runtime"`
+	EntryPoint        string `json:"entryPoint"`
+	AvailableMemoryMb int    `json:"availableMemoryMb"`
+	Timeout           string `json:"timeout"`
+	UpdateTime        string `json:"updateTime"`
+	VersionID         string `json:"versionId"`
+	HTTPSTrigger      struct {
+		URL string `json:"url"`
+	} `json:"httpsTrigger"`
+}
+
+func (GoogleCloudFunction) Status(directory string, cfg *config.Config, stg *settings.Settings, env string) (*cli.Report, error) {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+
+	report := cli.NewReport(cfg.ProjectName, env, "Google Cloud function")
+	report.Set("gcp_project", environment.ProjectName)
+	report.Set("region", environment.DeploymentRegion)
+
+	output, err := cli.ExecuteWithResult("gcloud", []string{
+		"functions",
+		"describe", cfg.ProjectName,
+		"--project", environment.ProjectID,
+		fmt.Sprintf("--region=%s", environment.DeploymentRegion),
+		"--format", "json",
+	}, "Querying for Cloud Function")
+	if err != nil {
+		if isNotFound(err) {
+			return report, nil
+		}
+		return nil, err
+	}
+	function := &cloudFunction{}
+	if err := json.Unmarshal(output, function); err != nil {
+		return nil, err
+	}
+
+	report.Deployed = true
+	report.Set("status", function.Status)
+	report.Set("runtime", function.Runtime)
+	report.Set("entry_point", function.EntryPoint)
+	report.Set("memory_mb", strconv.Itoa(function.AvailableMemoryMb))
+	report.Set("timeout", function.Timeout)
+	report.Set("version", function.VersionID)
+	report.Set("last_modified", function.UpdateTime)
+	report.Set("url", function.HTTPSTrigger.URL)
+
+	// Compare the function with kettle.json
+	if function.Runtime != cfg.Config.Runtime {
+		report.Mismatch("the runtime is %s, but kettle.json has %s", function.Runtime, cfg.Config.Runtime)
+	}
+	if function.EntryPoint != cfg.Config.EntryFunction {
+		report.Mismatch("the entry point is %s, but kettle.json has %s", function.EntryPoint, cfg.Config.EntryFunction)
+	}
+	if resources, err := cfg.ResolveResources(env); err == nil {
+		if resources.MemoryMB != 0 && resources.MemoryMB != function.AvailableMemoryMb {
+			report.Mismatch("the memory is %d MB, but kettle.json has %d MB", function.AvailableMemoryMb, resources.MemoryMB)
+		}
+		if timeout := fmt.Sprintf("%ds", resources.TimeoutSeconds); resources.TimeoutSeconds != 0 && timeout != function.Timeout {
+			report.Mismatch("the timeout is %s, but kettle.json has %s", function.Timeout, timeout)
+		}
+	}
+	return report, nil
+}
+
+func (GoogleCloudRun) Status(directory string, cfg *config.Config, stg *settings.Settings, env string) (*cli.Report, error) {
+	environment, err := getEnvironment(stg, env)
+	if err != nil {
+		return nil, err
+	}
+
+	report := cli.NewReport(cfg.ProjectName, env, "Cloud Run service")
+	report.Set("gcp_project", environment.ProjectName)
+	report.Set("region", environment.DeploymentRegion)
+
+	previous := cfg.Config.GoogleCloud.CloudRun[env]
+	service, err := describeService(cfg, environment)
+	if err != nil {
+		if isNotFound(err) {
+			if previous != nil {
+				report.Mismatch("kettle.json has a deployed image, but the service %s does not exist", cfg.ProjectName)
+			}
+			return report, nil
+		}
+		return nil, err
+	}
+
+	report.Deployed = true
+	report.Set("url", service.Status.URL)
+	report.Set("image", service.deployedImage())
+	report.Set("latest_revision", service.Status.LatestCreatedRevisionName)
+	report.Set("ready_revision", service.Status.LatestReadyRevisionName)
+	traffic := []string{}
+	for _, target := range service.Status.Traffic {
+		revision := target.RevisionName
+		if target.LatestRevision {
+			revision = fmt.Sprintf("%s (latest)", revision)
+		}
+		traffic = append(traffic, fmt.Sprintf("%s: %d%%", revision, target.Percent))
+	}
+	report.Set("traffic", strings.Join(traffic, ", "))
+	for _, condition := range service.Status.Conditions {
+		if condition.Type == "Ready" {
+			report.Set("ready", fmt.Sprintf("%s (since %s)", condition.Status, condition.LastTransitionTime))
+		}
+	}
+
+	// Compare the service with kettle.json
+	if previous != nil && previous.Image != service.deployedImage() {
+		report.Mismatch("the image is %s, but kettle last deployed %s", service.deployedImage(), previous.Image)
+	}
+	if service.Status.LatestCreatedRevisionName != service.Status.LatestReadyRevisionName {
+		report.Mismatch("the latest revision %s is not ready", service.Status.LatestCreatedRevisionName)
+	}
+	if runtimeConfig, err := getRuntimeConfiguration(directory, cfg, env); err == nil && !runtimeConfig.matches(service) {
+		report.Mismatch("the environment variables, secrets or resources are not the same as in kettle.json")
+	}
+	return report, nil
+}
+
+// isNotFound returns true if a gcloud command failed because a resource does not exist
+func isNotFound(err error) bool {
+	var commandErr *cli.CommandError
+	if !errors.As(err, &commandErr) {
+		return false
+	}
+	stderr := string(commandErr.Stderr)
+	return strings.Contains(stderr, "NOT_FOUND") || strings.Contains(stderr, "could not be found")
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
@@ -39,7 +38,7 @@ func init() {
 func validateCreateArgs(cmd *cobra.Command, args []string) error {
 	// Validate that a template was given
 	if len(args) == 0 {
-		return errors.New("please specify a template")
+		return cli.NewUserInputError("please specify a template")
 	}
 	return nil
 }
@@ -48,7 +47,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	// Get the directory where the template is (or has been cloned to)
 	templatePath, isTempDir, err := templates.GetTemplate(args[0])
 	if err != nil {
-		return formatError(err)
+		return err
 	}
 	if isTempDir {
 		defer os.RemoveAll(templatePath)
@@ -57,13 +56,13 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	// Read the template config
 	templateConfig, err := config.ReadConfig(templatePath)
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
 	}
 
 	// Create the directory where the template will be populated
 	projectName, directoryPath, err := createProjectDirectory()
 	if err != nil {
-		return formatError(err)
+		return err
 	}
 
 	// Ask the user for any input that is required
@@ -72,7 +71,7 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		"ProjectName": projectName,
 	}
 	for i, templateEntry := range templateConfig.Template {
-		userInput, err := cli.PromptForString(templateEntry.Prompt)
+		userInput, err := cli.PromptForString(fmt.Sprintf("template.%s", templateEntry.Key), templateEntry.Prompt)
 		if err != nil {
 			return cleanUp(directoryPath, err)
 		}
@@ -125,12 +124,13 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return cleanUp(directoryPath, err)
 	}
 	fmt.Println("\n✅  Created: ", directoryPath)
+	cli.GetResult().Path = directoryPath
 	return nil
 }
 
 func createProjectDirectory() (string, string, error) {
 	// Prompt the user for a project name
-	directoryName, err := cli.PromptForString("Project name")
+	directoryName, err := cli.PromptForString("project_name", "Project name")
 	if err != nil {
 		return "", "", err
 	}
@@ -141,7 +141,7 @@ func createProjectDirectory() (string, string, error) {
 	// Validate that the path does not exist
 	directoryPath, err := templates.NewProjectPath(directoryName)
 	if err != nil {
-		return "", "", err
+		return "", "", &cli.UserInputError{Err: err}
 	}
 
 	// Create a directory with the project name
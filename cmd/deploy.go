@@ -1,20 +1,25 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/clouds"
 	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/ignore"
 	"github.com/operatorai/kettle-cli/settings"
 	"github.com/operatorai/kettle-cli/templates"
 )
 
 var (
 	environment string
+	dryRun      bool
+	planFormat  string
+
+	showPackageContents bool
 
 	deployCmd = &cobra.Command{
 		Use:   "deploy",
@@ -28,13 +33,21 @@ var (
 
 func init() {
 	rootCmd.AddCommand(deployCmd)
-	deployCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to deploy to (GCP only)")
+	deployCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to deploy to")
+	deployCmd.Flags().BoolVar(&settings.ForceDeploy, "force", false, "Deploy even if the code has not changed")
+	deployCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the deployment plan without changing any cloud resources")
+	deployCmd.Flags().StringVar(&planFormat, "plan-format", "text", "Format of the --dry-run plan (text or json)")
+	deployCmd.Flags().MarkDeprecated("plan-format", "use --output json instead")
+	deployCmd.Flags().BoolVar(&showPackageContents, "show-package-contents", false, "List the project files that would be deployed (see .kettleignore)")
 }
 
 func validateDeployArgs(cmd *cobra.Command, args []string) error {
 	// Validate that args exist
 	if len(args) == 0 {
-		return errors.New("please specify a path or directory name")
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	if planFormat != "text" && planFormat != "json" {
+		return cli.NewUserInputError("unknown --plan-format: %s (text or json)", planFormat)
 	}
 	return nil
 }
@@ -44,41 +57,55 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 	// Construct the path we want to deploy from
 	deploymentPath, err := templates.GetProject(args)
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
 	}
 
 	// Read the template's config
 	templateConfig, err := config.ReadConfig(deploymentPath)
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
+	}
+
+	if showPackageContents {
+		return printPackageContents(deploymentPath)
 	}
 
 	// Read global settings
 	cloudSettings, err := settings.ReadSettings()
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
 	}
 
 	// Get the cloud provider & service type
 	cloudProvider, err := clouds.GetCloudProvider(templateConfig.Config.CloudProvider)
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
+	}
+
+	// In a dry run, cloud commands that would change anything are
+	// recorded in a plan instead of being run; this includes
+	// the provider's setup (e.g. creating an IAM role or a REST API)
+	var planRunner *cli.PlanRunner
+	if dryRun {
+		planRunner = cli.NewPlanRunner(cli.GetRunner())
+		cli.SetRunner(planRunner)
+		defer cli.SetRunner(planRunner.Runner)
 	}
 
 	// Set up the provider (if not done so already)
 	if err := cloudProvider.Setup(cloudSettings, false); err != nil {
-		return formatError(err)
+		return err
 	}
 
 	service, err := cloudProvider.GetService(templateConfig.Config.DeploymentType)
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
 	}
 
 	// Store the current directory before changing away from it
 	rootDir, err := os.Getwd()
 	if err != nil {
-		return formatError(err)
+		return err
 	}
 
 	// Change to the directory where the function to deploy is implemented
@@ -90,8 +117,23 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 	}()
 
 	// Deploy
-	if err := service.Deploy(deploymentPath, templateConfig, cloudSettings, environment); err != nil {
-		return formatError(err)
+	result := cli.GetResult()
+	result.Path = deploymentPath
+	result.Environment = environment
+	deployment, err := service.Deploy(deploymentPath, templateConfig, cloudSettings, environment)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		// The settings & config are not written back in a dry run
+		if cli.JSONOutput() {
+			result.Plan = planRunner.Plan
+			return nil
+		}
+		fmt.Println()
+		planRunner.Plan.WriteText(os.Stdout)
+		return nil
 	}
 
 	// Write the settings & config back (they may have been changed)
@@ -106,6 +148,35 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Record the deployment, so that it can be rolled back
+	if deployment != nil {
+		if err := recordDeployment(deploymentPath, deployment); err != nil {
+			if settings.DebugMode {
+				fmt.Println(err.Error())
+			}
+		}
+		result.Deployment = deployment
+	}
+
 	fmt.Println("✅  Deployed!")
 	return nil
 }
+
+// printPackageContents lists the project files that are not in .kettleignore
+func printPackageContents(deploymentPath string) error {
+	matcher, err := ignore.Load(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	files, err := ignore.Files(deploymentPath, matcher)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📦  %d file(s) would be deployed from %s:\n", len(files), deploymentPath)
+	for _, file := range files {
+		fmt.Println("  ", file)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+	"github.com/operatorai/kettle-cli/templates"
+)
+
+var (
+	skipConfirmation bool
+	deleteRole       bool
+
+	destroyCmd = &cobra.Command{
+		Use:   "destroy",
+		Short: "Tear down a project that you have deployed",
+		Long: `🧨 The kettle CLI tool can remove the services
+ that it has deployed to your cloud provider.`,
+		Args: validateDestroyArgs,
+		RunE: runDestroy,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(destroyCmd)
+	destroyCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to destroy")
+	destroyCmd.Flags().BoolVarP(&skipConfirmation, "yes", "y", false, "Destroy without asking for confirmation")
+	destroyCmd.Flags().BoolVar(&deleteRole, "delete-role", false, "Also delete the IAM role that kettle created (AWS only)")
+}
+
+func validateDestroyArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	return nil
+}
+
+func runDestroy(cmd *cobra.Command, args []string) error {
+	deploymentPath, err := templates.GetProject(args)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read the template's config
+	templateConfig, err := config.ReadConfig(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read global settings
+	cloudSettings, err := settings.ReadSettings()
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Get the cloud provider & service type
+	cloudProvider, err := clouds.GetCloudProvider(templateConfig.Config.CloudProvider)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	if err := cloudProvider.Setup(cloudSettings, false); err != nil {
+		return err
+	}
+
+	service, err := cloudProvider.GetService(templateConfig.Config.DeploymentType)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	setDestroyAnswers()
+	confirmed, err := cli.PromptToConfirm("destroy.confirm", fmt.Sprintf("Destroy %s", templateConfig.ProjectName))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("⏭  Cancelled")
+		return nil
+	}
+
+	destroyErr := service.Destroy(deploymentPath, templateConfig, cloudSettings, environment)
+
+	// Write the settings & config back, even if destroying failed part
+	// way through, so that they do not refer to deleted resources
+	if err := settings.WriteSettings(cloudSettings); err != nil {
+		if settings.DebugMode {
+			fmt.Println(err.Error())
+		}
+	}
+	if err := config.WriteConfig(deploymentPath, templateConfig); err != nil {
+		if settings.DebugMode {
+			fmt.Println(err.Error())
+		}
+	}
+	if destroyErr != nil {
+		return destroyErr
+	}
+
+	fmt.Println("✅  Destroyed!")
+	return nil
+}
+
+// setDestroyAnswers answers the prompts that --yes and --delete-role skip. --yes
+// answers every prompt, so the IAM role is only deleted if that has also been
+// asked for, with --delete-role or an answer for aws.delete_role
+func setDestroyAnswers() {
+	if skipConfirmation {
+		cli.SetAnswer("destroy.confirm", "yes")
+	}
+	if deleteRole {
+		cli.SetAnswer("aws.delete_role", "yes")
+	} else if skipConfirmation && !cli.HasAnswer("aws.delete_role") {
+		cli.SetAnswer("aws.delete_role", "no")
+	}
+}
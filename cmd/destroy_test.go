@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+)
+
+func TestSetDestroyAnswers(t *testing.T) {
+	defer func() {
+		skipConfirmation = false
+		deleteRole = false
+	}()
+
+	// The steps run in order, as answers that have been set are kept
+	tests := []struct {
+		name       string
+		yes        bool
+		deleteRole bool
+		env        string
+		want       bool
+	}{
+		{"--yes with an answer", true, false, "yes", true},
+		{"--yes", true, false, "", false},
+		{"--yes --delete-role", true, true, "", true},
+	}
+	for _, test := range tests {
+		skipConfirmation = test.yes
+		deleteRole = test.deleteRole
+		if test.env != "" {
+			os.Setenv(cli.AnswerEnvVar("aws.delete_role"), test.env)
+		}
+		setDestroyAnswers()
+
+		for _, key := range []string{"destroy.confirm", "aws.delete_role"} {
+			want := key == "destroy.confirm" || test.want
+			got, err := cli.PromptToConfirm(key, key)
+			if err != nil || got != want {
+				t.Errorf("%s: %s = %t, %v; want %t", test.name, key, got, err, want)
+			}
+		}
+		os.Unsetenv(cli.AnswerEnvVar("aws.delete_role"))
+	}
+}
@@ -26,32 +26,33 @@ func init() {
 
 func runInit(cmd *cobra.Command, args []string) error {
 	// Pick cloud provider to set up
-	cloudProviderName, err := cli.PromptForValue("Cloud to configure", clouds.SupportedClouds(), false)
+	cloudProviderName, err := cli.PromptForValue("cloud", "Cloud to configure", clouds.SupportedClouds(), false)
 	if err != nil {
-		return formatError(err)
+		return err
 	}
 
 	cloudProvider, err := clouds.GetCloudProvider(cloudProviderName)
 	if err != nil {
-		return formatError(err)
+		return &cli.UserInputError{Err: err}
 	}
 
 	// Read the existing settings
 	cloudSettings, err := settings.ReadSettings()
 	if err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
 	}
 
 	// Reset the values
 	if err := cloudProvider.Setup(cloudSettings, true); err != nil {
-		return formatError(err)
+		return err
 	}
 
 	// Write them back
 	if err := settings.WriteSettings(cloudSettings); err != nil {
-		return formatError(err)
+		return cli.NewConfigError(err)
 	}
 
 	fmt.Println("✅  Settings updated!")
+	cli.GetResult().Cloud = cloudProviderName
 	return nil
 }
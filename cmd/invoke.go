@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/invoke"
+	"github.com/operatorai/kettle-cli/settings"
+	"github.com/operatorai/kettle-cli/templates"
+)
+
+var (
+	invokeData     string
+	invokeDataFile string
+	invokeOptions  = &invoke.Options{}
+
+	invokeCmd = &cobra.Command{
+		Use:   "invoke",
+		Short: "Call a project that you have deployed",
+		Long: `📞 The kettle CLI tool can send a payload to the
+ services that it has deployed to your cloud provider.`,
+		Args: validateInvokeArgs,
+		RunE: runInvoke,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(invokeCmd)
+	invokeCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to invoke")
+	invokeCmd.Flags().StringVarP(&invokeData, "data", "d", "", "JSON payload to send")
+	invokeCmd.Flags().StringVar(&invokeDataFile, "data-file", "", "File with the payload to send (- for stdin)")
+	invokeCmd.Flags().StringVar(&invokeOptions.APIKey, "api-key", "", "API key for REST APIs that require one (AWS only)")
+	invokeCmd.Flags().BoolVar(&invokeOptions.IdentityToken, "identity-token", false, "Send an identity token from gcloud (Google Cloud only)")
+	invokeCmd.Flags().BoolVar(&invokeOptions.Direct, "direct", false, "Invoke the function directly instead of its API endpoint (AWS only)")
+}
+
+func validateInvokeArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	if invokeData != "" && invokeDataFile != "" {
+		return cli.NewUserInputError("please specify either --data or --data-file")
+	}
+	return nil
+}
+
+func runInvoke(cmd *cobra.Command, args []string) error {
+	deploymentPath, err := templates.GetProject(args)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	payload, err := readPayload()
+	if err != nil {
+		return err
+	}
+
+	// Read the template's config
+	templateConfig, err := config.ReadConfig(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read global settings
+	cloudSettings, err := settings.ReadSettings()
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Get the cloud provider & service type
+	cloudProvider, err := clouds.GetCloudProvider(templateConfig.Config.CloudProvider)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	if err := cloudProvider.Setup(cloudSettings, false); err != nil {
+		return err
+	}
+
+	service, err := cloudProvider.GetService(templateConfig.Config.DeploymentType)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Call the HTTP endpoint if there is one, or invoke the function directly
+	var endpoint *invoke.Endpoint
+	if !invokeOptions.Direct {
+		endpoint, err = service.Endpoint(deploymentPath, templateConfig, cloudSettings, environment, invokeOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	var response *invoke.Response
+	if endpoint != nil {
+		fmt.Printf("📞  Invoking: %s\n", endpoint.URL)
+		response, err = invoke.Post(endpoint, payload)
+		if err != nil {
+			return err
+		}
+	} else {
+		directInvoker, ok := service.(clouds.DirectInvoker)
+		if !ok {
+			return cli.NewUserInputError("%s does not have an endpoint to invoke", templateConfig.ProjectName)
+		}
+		fmt.Printf("📞  Invoking: %s (directly)\n", templateConfig.ProjectName)
+		response, err = directInvoker.Invoke(deploymentPath, templateConfig, cloudSettings, environment, payload)
+		if err != nil {
+			return err
+		}
+	}
+
+	response.Write(os.Stdout)
+	result := cli.GetResult()
+	if endpoint != nil {
+		result.EndpointURL = endpoint.URL
+	}
+	result.Response = &cli.ResultResponse{
+		StatusCode: response.StatusCode,
+		Latency:    response.Latency.String(),
+		Body:       string(response.Body),
+	}
+	if !response.Succeeded() {
+		return fmt.Errorf("%s failed", templateConfig.ProjectName)
+	}
+	return nil
+}
+
+// readPayload reads the payload from --data, --data-file, or from stdin
+// if it is piped into kettle; the default payload is an empty JSON object
+func readPayload() ([]byte, error) {
+	if invokeData != "" {
+		return []byte(invokeData), nil
+	}
+
+	// Stdin is read if it is not a terminal, i.e. a pipe or a redirected file
+	readStdin := invokeDataFile == "-"
+	if invokeDataFile == "" {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+			readStdin = true
+		}
+	}
+	if readStdin {
+		payload, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, &cli.UserInputError{Err: err}
+		}
+		// An empty stdin (e.g. in CI) is only used if it was asked for
+		if len(payload) > 0 || invokeDataFile == "-" {
+			return payload, nil
+		}
+	}
+
+	if invokeDataFile != "" {
+		payload, err := ioutil.ReadFile(invokeDataFile)
+		if err != nil {
+			return nil, &cli.UserInputError{Err: err}
+		}
+		return payload, nil
+	}
+	return []byte("{}"), nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/logs"
+	"github.com/operatorai/kettle-cli/settings"
+	"github.com/operatorai/kettle-cli/templates"
+)
+
+var (
+	logOptions = &logs.Options{}
+
+	logsCmd = &cobra.Command{
+		Use:   "logs",
+		Short: "Read the logs of a project that you have deployed",
+		Long: `📜 The kettle CLI tool can read and follow the logs
+ of the services that it has deployed to your cloud provider.`,
+		Args: validateLogsArgs,
+		RunE: runLogs,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to read the logs of")
+	logsCmd.Flags().BoolVarP(&logOptions.Follow, "follow", "f", false, "Keep streaming new log entries")
+	logsCmd.Flags().StringVar(&logOptions.Since, "since", "10m", "How far back to read, e.g. 30s, 10m, 2h or 1d")
+	logsCmd.Flags().StringVar(&logOptions.Filter, "filter", "", "Only show entries that match a filter in the cloud's logging syntax")
+	logsCmd.Flags().BoolVar(&jsonOutputAlias, "json", false, "Print log entries as JSON")
+	logsCmd.Flags().MarkDeprecated("json", "use --output json instead")
+}
+
+func validateLogsArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	if _, err := logOptions.SinceDuration(); err != nil {
+		return &cli.UserInputError{Err: err}
+	}
+	return nil
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	deploymentPath, err := templates.GetProject(args)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read the template's config
+	templateConfig, err := config.ReadConfig(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read global settings
+	cloudSettings, err := settings.ReadSettings()
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Get the cloud provider & service type
+	cloudProvider, err := clouds.GetCloudProvider(templateConfig.Config.CloudProvider)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	if err := cloudProvider.Setup(cloudSettings, false); err != nil {
+		return err
+	}
+
+	service, err := cloudProvider.GetService(templateConfig.Config.DeploymentType)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Log entries are written to stdout as they are read, instead of a result
+	if cli.JSONOutput() {
+		logOptions.JSON = true
+		cli.SkipResult()
+	}
+
+	// Ctrl+C stops following the logs; the cloud's cli exits, and kettle exits cleanly
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	err = service.Logs(deploymentPath, templateConfig, cloudSettings, environment, logOptions)
+	if err != nil {
+		select {
+		case <-interrupted:
+			return nil
+		default:
+			return err
+		}
+	}
+	return nil
+}
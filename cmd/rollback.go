@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+	"github.com/operatorai/kettle-cli/templates"
+)
+
+var (
+	rollbackTo string
+
+	rollbackCmd = &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll back a project to the version before the live one",
+		Long: `⏪ The kettle CLI tool can move traffic back to an earlier
+ version of a project that it has deployed.`,
+		Args: validateRollbackArgs,
+		RunE: runRollback,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to roll back")
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "Version (AWS) or revision (Google Cloud Run) to roll back to")
+	rollbackCmd.Flags().BoolVarP(&skipConfirmation, "yes", "y", false, "Roll back without asking for confirmation")
+}
+
+func validateRollbackArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	return nil
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	deploymentPath, err := templates.GetProject(args)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read the template's config & deployment history
+	templateConfig, err := config.ReadConfig(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	history, err := config.ReadHistory(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read global settings
+	cloudSettings, err := settings.ReadSettings()
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Get the cloud provider & service type
+	cloudProvider, err := clouds.GetCloudProvider(templateConfig.Config.CloudProvider)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	if err := cloudProvider.Setup(cloudSettings, false); err != nil {
+		return err
+	}
+
+	service, err := cloudProvider.GetService(templateConfig.Config.DeploymentType)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	rollbacker, ok := service.(clouds.Rollbacker)
+	if !ok {
+		return cli.NewUserInputError("%s deployments cannot be rolled back", templateConfig.Config.DeploymentType)
+	}
+
+	if skipConfirmation {
+		cli.SetAnswer("rollback.confirm", "yes")
+	}
+	confirmed, err := cli.PromptToConfirm("rollback.confirm", fmt.Sprintf("Roll back %s", templateConfig.ProjectName))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("⏭  Cancelled")
+		return nil
+	}
+
+	deployment, err := rollbacker.Rollback(deploymentPath, templateConfig, cloudSettings, environment, history, rollbackTo)
+	if err != nil {
+		return err
+	}
+	deployment.Rollback = true
+	if err := recordDeployment(deploymentPath, deployment); err != nil {
+		if settings.DebugMode {
+			fmt.Println(err.Error())
+		}
+	}
+	result := cli.GetResult()
+	result.Environment = environment
+	result.Deployment = deployment
+
+	fmt.Println("✅  Rolled back!")
+	return nil
+}
+
+// recordDeployment adds a deployment to the project's history
+func recordDeployment(deploymentPath string, deployment *config.Deployment) error {
+	history, err := config.ReadHistory(deploymentPath)
+	if err != nil {
+		return err
+	}
+
+	deployment.Timestamp = time.Now().UTC()
+	deployment.Environment = environment
+	if !deployment.Rollback {
+		deployment.GitSHA = getGitSHA(deploymentPath)
+	}
+	history.Add(deployment)
+	return config.WriteHistory(deploymentPath, history)
+}
+
+// getGitSHA returns "" if the project is not in a git repository
+func getGitSHA(deploymentPath string) string {
+	output, err := cli.ExecuteWithResult("git", []string{
+		"-C", deploymentPath,
+		"rev-parse",
+		"HEAD",
+	}, "Reading the git commit")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -4,28 +4,108 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/operatorai/kettle-cli/cli"
 	"github.com/operatorai/kettle-cli/settings"
 	"github.com/spf13/cobra"
 )
 
+var (
+	recordSession string
+	replaySession string
+	answersFile   string
+	answers       []string
+
+	// Set by the deprecated --json flags of logs & status; they (and
+	// deploy --plan-format json) are aliases of --output json
+	jsonOutputAlias bool
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "kettle",
 	Short: "A CLI tool for creating http functions or services",
 	Long: "\n🎯 The kettle CLI creates machine learning pipelines" +
 		"\n or microservices from templates.",
+	PersistentPreRunE: preRun,
+	SilenceErrors:     true,
+	SilenceUsage:      true,
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&settings.DebugMode, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringVarP(&settings.OutputFormat, "output", "o", settings.OutputFormatText, "Output format (text or json)")
+
+	// Answers to prompts, for running without any user input (e.g. in CI)
+	rootCmd.PersistentFlags().BoolVar(&settings.NonInteractive, "non-interactive", false, "Fail instead of prompting for input")
+	rootCmd.PersistentFlags().StringVar(&answersFile, "answers-file", "", "YAML or JSON file with answers to prompts")
+	rootCmd.PersistentFlags().StringArrayVar(&answers, "answer", []string{}, "Answer to a prompt, as key=value")
+
+	// Record or replay the commands (e.g. aws, gcloud) that kettle runs
+	rootCmd.PersistentFlags().StringVar(&recordSession, "record-session", "", "Record the commands that are run to a file")
+	rootCmd.PersistentFlags().StringVar(&replaySession, "replay-session", "", "Replay the commands from a recorded file")
+	rootCmd.PersistentFlags().MarkHidden("record-session")
+	rootCmd.PersistentFlags().MarkHidden("replay-session")
+
+	// Errors in flags (e.g. unknown flags) are the user's input
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &cli.UserInputError{Err: err}
+	})
+}
+
+func preRun(cmd *cobra.Command, args []string) error {
+	if jsonOutputAlias || planFormat == "json" {
+		settings.OutputFormat = settings.OutputFormatJSON
+	}
+	switch settings.OutputFormat {
+	case settings.OutputFormatText:
+	case settings.OutputFormatJSON:
+		cli.UseJSONOutput()
+	default:
+		return cli.NewUserInputError("unknown --output: %s (text or json)", settings.OutputFormat)
+	}
+	if err := setRunner(); err != nil {
+		return err
+	}
+	if value, ok := os.LookupEnv("KETTLE_NON_INTERACTIVE"); ok && !cmd.Flags().Changed("non-interactive") {
+		settings.NonInteractive = value == "true" || value == "1"
+	}
+	return cli.LoadAnswers(answersFile, answers)
+}
+
+func setRunner() error {
+	if recordSession != "" && replaySession != "" {
+		return cli.NewUserInputError("--record-session and --replay-session cannot be used together")
+	}
+	if recordSession != "" {
+		cli.SetRunner(cli.NewRecordingRunner(cli.GetRunner(), recordSession))
+	}
+	if replaySession != "" {
+		replayRunner, err := cli.NewReplayRunner(replaySession)
+		if err != nil {
+			return cli.NewConfigError(err)
+		}
+		cli.SetRunner(replayRunner)
+	}
+	return nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// The exit code depends on the type of error (see cli.ExitCode)
+//
+// With --output json, a result document is written to stdout, whether
+// or not the command succeeds (see cli.Result)
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if cli.JSONOutput() {
+		cli.UseJSONOutput()
+		if writeErr := cli.WriteResult(cmd.Name(), err); writeErr != nil && settings.DebugMode {
+			fmt.Println(writeErr.Error())
+		}
+	}
+	if err != nil {
+		fmt.Printf("\n❌ %s\n", err.Error())
+		os.Exit(cli.ExitCode(err))
 	}
 }
 
@@ -40,8 +120,3 @@ func init() {
 	// when this action is called directly.
 	// rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
-
-func formatError(err error) error {
-	fmt.Printf("\n❌ %s\n", err.Error())
-	return nil
-}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+func TestJSONOutputAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default", []string{"status", "hello"}, settings.OutputFormatText},
+		{"output flag", []string{"status", "hello", "--output", "json"}, settings.OutputFormatJSON},
+		{"status --json", []string{"status", "hello", "--json"}, settings.OutputFormatJSON},
+		{"logs --json", []string{"logs", "hello", "--json"}, settings.OutputFormatJSON},
+		{"deploy --plan-format json", []string{"deploy", "hello", "--dry-run", "--plan-format", "json"}, settings.OutputFormatJSON},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout := os.Stdout
+			defer func() {
+				os.Stdout = stdout
+				settings.OutputFormat = settings.OutputFormatText
+				jsonOutputAlias = false
+				planFormat = "text"
+				dryRun = false
+			}()
+
+			cmd, _, err := rootCmd.Find(test.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cmd.ParseFlags(test.args[1:]); err != nil {
+				t.Fatal(err)
+			}
+			if err := preRun(cmd, cmd.Flags().Args()); err != nil {
+				t.Fatal(err)
+			}
+			if settings.OutputFormat != test.want {
+				t.Errorf("output format = %s, want %s", settings.OutputFormat, test.want)
+			}
+		})
+	}
+}
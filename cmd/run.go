@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/emulator"
+	"github.com/operatorai/kettle-cli/templates"
+)
+
+var (
+	noReload   bool
+	runOptions = &emulator.Options{}
+
+	runCmd = &cobra.Command{
+		Use:   "run",
+		Short: "Run a project locally",
+		Long: `🏃 The kettle CLI tool can run your project on localhost,
+ in the same way that it will be called when it is deployed.`,
+		Args: validateRunArgs,
+		RunE: runRun,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to use the variables of")
+	runCmd.Flags().IntVarP(&runOptions.Port, "port", "p", 8080, "Port to serve the project on")
+	runCmd.Flags().BoolVar(&noReload, "no-reload", false, "Do not restart the project when its files change")
+}
+
+func validateRunArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	if runOptions.Port <= 0 || runOptions.Port > 65535 {
+		return cli.NewUserInputError("invalid port: %d", runOptions.Port)
+	}
+	return nil
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	// The project's output is written to stdout while it is running, instead of a result
+	cli.SkipResult()
+
+	deploymentPath, err := templates.GetProject(args)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read the template's config
+	templateConfig, err := config.ReadConfig(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Store the current directory before changing away from it
+	rootDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	// Change to the directory where the project is implemented
+	os.Chdir(deploymentPath)
+	defer func() {
+		// Return to the original root directory
+		os.Chdir(rootDir)
+	}()
+
+	runOptions.Reload = !noReload
+	return emulator.Run(deploymentPath, templateConfig, environment, runOptions)
+}
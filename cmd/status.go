@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+	"github.com/operatorai/kettle-cli/templates"
+)
+
+var (
+	statusCmd = &cobra.Command{
+		Use:     "status",
+		Aliases: []string{"describe"},
+		Short:   "Show what is deployed for a project",
+		Long: `🔍 The kettle CLI tool can report what it has deployed to your
+ cloud provider, and whether that matches the project's kettle.json.`,
+		Args: validateStatusArgs,
+		RunE: runStatus,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringVarP(&environment, "env", "e", "", "Environment to report on")
+	statusCmd.Flags().BoolVar(&jsonOutputAlias, "json", false, "Print the status as JSON")
+	statusCmd.Flags().MarkDeprecated("json", "use --output json instead")
+}
+
+func validateStatusArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cli.NewUserInputError("please specify a path or directory name")
+	}
+	return nil
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	deploymentPath, err := templates.GetProject(args)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read the template's config
+	templateConfig, err := config.ReadConfig(deploymentPath)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Read global settings
+	cloudSettings, err := settings.ReadSettings()
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	// Get the cloud provider & service type
+	cloudProvider, err := clouds.GetCloudProvider(templateConfig.Config.CloudProvider)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+	if err := cloudProvider.Setup(cloudSettings, false); err != nil {
+		return err
+	}
+
+	service, err := cloudProvider.GetService(templateConfig.Config.DeploymentType)
+	if err != nil {
+		return cli.NewConfigError(err)
+	}
+
+	report, err := service.Status(deploymentPath, templateConfig, cloudSettings, environment)
+	if err != nil {
+		return err
+	}
+	if cli.JSONOutput() {
+		cli.GetResult().Report = report
+		return nil
+	}
+	report.WriteText(os.Stdout)
+	return nil
+}
@@ -6,6 +6,8 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/operatorai/kettle-cli/cli"
+
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +22,7 @@ var versionCmd = &cobra.Command{
 	Long:  `🔢 Prints the installed version of the kettle CLI.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println(Version)
+		cli.GetResult().Version = Version
 	},
 }
 
@@ -52,3 +52,31 @@ func pathExists(path string) (bool, error) {
 	}
 	return true, nil
 }
+
+// AWSDeployment returns the state of the project's Lambda function in an
+// environment; the default environment ("") is stored at the top level. An
+// environment that has not been deployed has an empty state, which is not stored
+func (c *Config) AWSDeployment(env string) *AWSDeployment {
+	if env == "" {
+		return &c.Config.AWS.AWSDeployment
+	}
+	if deployment, ok := c.Config.AWS.Environments[env]; ok && deployment != nil {
+		return deployment
+	}
+	return &AWSDeployment{}
+}
+
+// EnsureAWSDeployment returns the state of the project's Lambda function in an
+// environment, and adds it to the config (to be written back) if it does not exist
+func (c *Config) EnsureAWSDeployment(env string) *AWSDeployment {
+	if env == "" {
+		return &c.Config.AWS.AWSDeployment
+	}
+	if c.Config.AWS.Environments == nil {
+		c.Config.AWS.Environments = map[string]*AWSDeployment{}
+	}
+	if c.Config.AWS.Environments[env] == nil {
+		c.Config.AWS.Environments[env] = &AWSDeployment{}
+	}
+	return c.Config.AWS.Environments[env]
+}
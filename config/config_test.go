@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestAWSDeployment(t *testing.T) {
+	cfg := &Config{}
+
+	// Reading an environment's state does not add it to the config
+	if deployment := cfg.AWSDeployment("prod"); deployment == nil || deployment.CodeSha256 != "" {
+		t.Fatalf("AWSDeployment() = %+v, want an empty state", deployment)
+	}
+	if len(cfg.Config.AWS.Environments) != 0 {
+		t.Errorf("environments = %v after a read, want none", cfg.Config.AWS.Environments)
+	}
+
+	cfg.EnsureAWSDeployment("prod").CodeSha256 = "abc"
+	if got := cfg.AWSDeployment("prod").CodeSha256; got != "abc" {
+		t.Errorf("AWSDeployment().CodeSha256 = %q, want abc", got)
+	}
+
+	// The default environment is stored at the top level
+	cfg.EnsureAWSDeployment("").CodeSha256 = "def"
+	if cfg.Config.AWS.CodeSha256 != "def" || cfg.AWSDeployment("") != &cfg.Config.AWS.AWSDeployment {
+		t.Errorf("the default environment is not stored at the top level")
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// Environment is the runtime configuration (environment variables & secrets)
+// of a function or service. Values in env files are read first, and then
+// replaced by any variables with the same name
+type Environment struct {
+	Variables map[string]string `json:"variables,omitempty"`
+	EnvFiles  []string          `json:"env_files,omitempty"`
+
+	// Secrets map environment variable names to references in a secret manager:
+	// 	AWS: secretsmanager:<secret-id> or ssm:<parameter-name>
+	// 	Google Cloud: <secret>:<version> (or <secret> for the latest version)
+	Secrets map[string]string `json:"secrets,omitempty"`
+
+	// Overrides are applied on top of the values above when
+	// deploying to an environment (e.g. --env prod)
+	Overrides map[string]*Environment `json:"overrides,omitempty"`
+}
+
+// ResolvedEnvironment is the runtime configuration for a deployment
+type ResolvedEnvironment struct {
+	Variables map[string]string
+	Secrets   map[string]string
+}
+
+// ResolveEnvironment merges the project's runtime configuration with the overrides
+// for an environment, reading any env files relative to the project directory
+func (c *Config) ResolveEnvironment(directory, env string) (*ResolvedEnvironment, error) {
+	resolved := &ResolvedEnvironment{
+		Variables: map[string]string{},
+		Secrets:   map[string]string{},
+	}
+	if c.Config.Environment == nil {
+		return resolved, nil
+	}
+
+	layers := []*Environment{c.Config.Environment}
+	if override, ok := c.Config.Environment.Overrides[env]; ok && env != "" {
+		layers = append(layers, override)
+	}
+
+	for _, layer := range layers {
+		for _, envFile := range layer.EnvFiles {
+			values, err := readEnvFile(path.Join(directory, envFile))
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range values {
+				resolved.Variables[key] = value
+			}
+		}
+		for key, value := range layer.Variables {
+			resolved.Variables[key] = value
+		}
+		for key, reference := range layer.Secrets {
+			resolved.Secrets[key] = reference
+		}
+	}
+
+	// A name is either a variable or a secret
+	for key := range resolved.Secrets {
+		if _, ok := resolved.Variables[key]; ok {
+			return nil, fmt.Errorf("%s is both an environment variable and a secret", key)
+		}
+	}
+	return resolved, nil
+}
+
+// readEnvFile reads KEY=VALUE lines from a .env file
+func readEnvFile(filePath string) (map[string]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", filePath, lineNumber)
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestResolveEnvironment(t *testing.T) {
+	directory := t.TempDir()
+	envFiles := map[string]string{
+		".env":      "# comment\nexport FROM_FILE=base\nQUOTED=\"a b\"\nOVERRIDDEN=file\n",
+		".env.prod": "FROM_FILE=prod\n",
+	}
+	for name, contents := range envFiles {
+		if err := os.WriteFile(path.Join(directory, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	environment := &Environment{
+		EnvFiles:  []string{".env"},
+		Variables: map[string]string{"OVERRIDDEN": "variable", "LEVEL": "debug"},
+		Secrets:   map[string]string{"TOKEN": "ssm:/dev/token"},
+		Overrides: map[string]*Environment{
+			"prod": {
+				EnvFiles:  []string{".env.prod"},
+				Variables: map[string]string{"LEVEL": "info"},
+				Secrets:   map[string]string{"TOKEN": "ssm:/prod/token"},
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		env         string
+		wantVars    map[string]string
+		wantSecrets map[string]string
+	}{
+		{
+			name:        "default",
+			env:         "",
+			wantVars:    map[string]string{"FROM_FILE": "base", "QUOTED": "a b", "OVERRIDDEN": "variable", "LEVEL": "debug"},
+			wantSecrets: map[string]string{"TOKEN": "ssm:/dev/token"},
+		},
+		{
+			name:        "override",
+			env:         "prod",
+			wantVars:    map[string]string{"FROM_FILE": "prod", "QUOTED": "a b", "OVERRIDDEN": "variable", "LEVEL": "info"},
+			wantSecrets: map[string]string{"TOKEN": "ssm:/prod/token"},
+		},
+		{
+			name:        "environment without overrides",
+			env:         "staging",
+			wantVars:    map[string]string{"FROM_FILE": "base", "QUOTED": "a b", "OVERRIDDEN": "variable", "LEVEL": "debug"},
+			wantSecrets: map[string]string{"TOKEN": "ssm:/dev/token"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Config.Environment = environment
+			resolved, err := cfg.ResolveEnvironment(directory, test.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resolved.Variables, test.wantVars) {
+				t.Errorf("variables = %v, want %v", resolved.Variables, test.wantVars)
+			}
+			if !reflect.DeepEqual(resolved.Secrets, test.wantSecrets) {
+				t.Errorf("secrets = %v, want %v", resolved.Secrets, test.wantSecrets)
+			}
+		})
+	}
+}
+
+func TestResolveEnvironmentErrors(t *testing.T) {
+	directory := t.TempDir()
+	if err := os.WriteFile(path.Join(directory, ".env"), []byte("NOT A VARIABLE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		environment *Environment
+	}{
+		{"variable and secret", &Environment{
+			Variables: map[string]string{"TOKEN": "x"},
+			Secrets:   map[string]string{"TOKEN": "ssm:/token"},
+		}},
+		{"invalid env file", &Environment{EnvFiles: []string{".env"}}},
+		{"missing env file", &Environment{EnvFiles: []string{".env.missing"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Config.Environment = test.environment
+			if _, err := cfg.ResolveEnvironment(directory, ""); err == nil {
+				t.Error("ResolveEnvironment() did not return an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	historyDirectory = ".kettle"
+	historyFileName  = "history.json"
+)
+
+// Deployment is a successful deployment (or rollback) of a project,
+// which is recorded in .kettle/history.json
+type Deployment struct {
+	Timestamp        time.Time `json:"timestamp"`
+	Environment      string    `json:"environment,omitempty"`
+	GitSHA           string    `json:"git_sha,omitempty"`
+	PackageHash      string    `json:"package_hash,omitempty"`
+	LambdaVersionArn string    `json:"lambda_version_arn,omitempty"`
+	CloudRunRevision string    `json:"cloud_run_revision,omitempty"`
+	Rollback         bool      `json:"rollback,omitempty"`
+}
+
+// History is the list of deployments of a project, oldest first
+type History struct {
+	Deployments []*Deployment `json:"deployments"`
+}
+
+// ReadHistory returns an empty history if the project has not been deployed
+func ReadHistory(directory string) (*History, error) {
+	history := &History{
+		Deployments: []*Deployment{},
+	}
+	data, err := os.ReadFile(path.Join(directory, historyDirectory, historyFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func WriteHistory(directory string, history *History) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Join(directory, historyDirectory), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(directory, historyDirectory, historyFileName), data, 0644)
+}
+
+func (h *History) Add(deployment *Deployment) {
+	h.Deployments = append(h.Deployments, deployment)
+}
+
+// Previous returns the deployment (not a rollback) in an environment that came before
+// the live version, which is identified with version (e.g. a Cloud Run revision).
+// It returns nil if there is no earlier version to roll back to
+func (h *History) Previous(env, live string, version func(*Deployment) string) *Deployment {
+	deployments := []*Deployment{}
+	for _, deployment := range h.Deployments {
+		if deployment.Environment == env && !deployment.Rollback && version(deployment) != "" {
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	// Start from the live version, or from the most recent
+	// deployment if the live version is not in the history
+	start := len(deployments) - 1
+	for i := len(deployments) - 1; i >= 0; i-- {
+		if version(deployments[i]) == live {
+			start = i
+			break
+		}
+	}
+	for i := start; i >= 0; i-- {
+		if version(deployments[i]) != live {
+			return deployments[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the most recent deployment (not a rollback) of a version in an environment
+func (h *History) Find(env, value string, version func(*Deployment) string) *Deployment {
+	for i := len(h.Deployments) - 1; i >= 0; i-- {
+		deployment := h.Deployments[i]
+		if deployment.Environment == env && !deployment.Rollback && version(deployment) == value {
+			return deployment
+		}
+	}
+	return nil
+}
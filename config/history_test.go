@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+)
+
+func revision(deployment *Deployment) string {
+	return deployment.CloudRunRevision
+}
+
+func TestHistoryPrevious(t *testing.T) {
+	history := &History{}
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-1"})
+	history.Add(&Deployment{Environment: "dev", CloudRunRevision: "hello-dev-1"})
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-2"})
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-3"})
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-2", Rollback: true})
+	history.Add(&Deployment{Environment: "prod"})
+
+	tests := []struct {
+		name string
+		env  string
+		live string
+		want string
+	}{
+		{"latest is live", "prod", "hello-3", "hello-2"},
+		{"after a rollback", "prod", "hello-2", "hello-1"},
+		{"oldest is live", "prod", "hello-1", ""},
+		{"live version is not in the history", "prod", "hello-4", "hello-3"},
+		{"other environment", "dev", "hello-dev-1", ""},
+		{"no deployments", "staging", "hello-staging-1", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ""
+			if previous := history.Previous(test.env, test.live, revision); previous != nil {
+				got = previous.CloudRunRevision
+			}
+			if got != test.want {
+				t.Errorf("Previous(%s, %s) = %q, want %q", test.env, test.live, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHistoryFind(t *testing.T) {
+	history := &History{}
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-1", GitSHA: "aaa"})
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-2", GitSHA: "bbb"})
+	history.Add(&Deployment{Environment: "prod", CloudRunRevision: "hello-1", Rollback: true})
+
+	if found := history.Find("prod", "hello-1", revision); found == nil || found.GitSHA != "aaa" {
+		t.Errorf("Find(hello-1) = %+v, want the deployment from aaa", found)
+	}
+	if found := history.Find("dev", "hello-1", revision); found != nil {
+		t.Errorf("Find() in another environment = %+v, want nil", found)
+	}
+}
+
+func TestReadAndWriteHistory(t *testing.T) {
+	directory := t.TempDir()
+	history, err := ReadHistory(directory)
+	if err != nil || len(history.Deployments) != 0 {
+		t.Fatalf("ReadHistory() of a new project = %+v, %v", history, err)
+	}
+
+	history.Add(&Deployment{Environment: "prod", PackageHash: "abc"})
+	if err := WriteHistory(directory, history); err != nil {
+		t.Fatal(err)
+	}
+	history, err = ReadHistory(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Deployments) != 1 || history.Deployments[0].PackageHash != "abc" {
+		t.Errorf("ReadHistory() = %+v", history.Deployments)
+	}
+}
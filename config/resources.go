@@ -0,0 +1,69 @@
+package config
+
+import "fmt"
+
+// Resources are the memory, timeout, concurrency and scaling settings of a
+// function or service. Settings that are not set are left unchanged in the cloud
+type Resources struct {
+	MemoryMB       int    `json:"memory_mb,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	CPU            string `json:"cpu,omitempty"`
+
+	// Concurrency is the maximum number of requests per instance (Cloud Run only)
+	Concurrency  int  `json:"concurrency,omitempty"`
+	MinInstances *int `json:"min_instances,omitempty"`
+	MaxInstances *int `json:"max_instances,omitempty"`
+
+	// Overrides are applied on top of the values above when
+	// deploying to an environment (e.g. --env prod)
+	Overrides map[string]*Resources `json:"overrides,omitempty"`
+}
+
+// ResolveResources merges the project's resources with the overrides for an environment
+func (c *Config) ResolveResources(env string) (*Resources, error) {
+	resolved := &Resources{}
+	if c.Config.Resources == nil {
+		return resolved, nil
+	}
+
+	layers := []*Resources{c.Config.Resources}
+	if override, ok := c.Config.Resources.Overrides[env]; ok && env != "" {
+		layers = append(layers, override)
+	}
+	for _, layer := range layers {
+		if layer.MemoryMB != 0 {
+			resolved.MemoryMB = layer.MemoryMB
+		}
+		if layer.TimeoutSeconds != 0 {
+			resolved.TimeoutSeconds = layer.TimeoutSeconds
+		}
+		if layer.CPU != "" {
+			resolved.CPU = layer.CPU
+		}
+		if layer.Concurrency != 0 {
+			resolved.Concurrency = layer.Concurrency
+		}
+		if layer.MinInstances != nil {
+			resolved.MinInstances = layer.MinInstances
+		}
+		if layer.MaxInstances != nil {
+			resolved.MaxInstances = layer.MaxInstances
+		}
+	}
+
+	switch {
+	case resolved.MemoryMB < 0:
+		return nil, fmt.Errorf("memory_mb must be positive: %d", resolved.MemoryMB)
+	case resolved.TimeoutSeconds < 0:
+		return nil, fmt.Errorf("timeout_seconds must be positive: %d", resolved.TimeoutSeconds)
+	case resolved.Concurrency < 0:
+		return nil, fmt.Errorf("concurrency must be positive: %d", resolved.Concurrency)
+	case resolved.MinInstances != nil && *resolved.MinInstances < 0:
+		return nil, fmt.Errorf("min_instances must not be negative: %d", *resolved.MinInstances)
+	case resolved.MaxInstances != nil && *resolved.MaxInstances < 0:
+		return nil, fmt.Errorf("max_instances must not be negative: %d", *resolved.MaxInstances)
+	case resolved.MinInstances != nil && resolved.MaxInstances != nil && *resolved.MinInstances > *resolved.MaxInstances:
+		return nil, fmt.Errorf("min_instances (%d) is greater than max_instances (%d)", *resolved.MinInstances, *resolved.MaxInstances)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveResources(t *testing.T) {
+	one, two, three := 1, 2, 3
+	resources := &Resources{
+		MemoryMB:     256,
+		MaxInstances: &three,
+		Overrides: map[string]*Resources{
+			"prod":    {MemoryMB: 1024, MinInstances: &one},
+			"invalid": {MinInstances: &three, MaxInstances: &two},
+		},
+	}
+	tests := []struct {
+		env     string
+		want    *Resources
+		wantErr bool
+	}{
+		{env: "", want: &Resources{MemoryMB: 256, MaxInstances: &three}},
+		{env: "prod", want: &Resources{MemoryMB: 1024, MinInstances: &one, MaxInstances: &three}},
+		{env: "invalid", wantErr: true},
+	}
+	for _, test := range tests {
+		cfg := &Config{}
+		cfg.Config.Resources = resources
+		got, err := cfg.ResolveResources(test.env)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: ResolveResources() did not return an error", test.env)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: ResolveResources() = %+v, want %+v", test.env, got, test.want)
+		}
+	}
+}
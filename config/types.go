@@ -10,14 +10,23 @@ const (
 type Config struct {
 	ProjectName string `json:"name"`
 	Config      struct {
-		Runtime        string `json:"runtime"`
-		PythonManager  string `json:"python_manager,omitempty"`
-		CloudProvider  string `json:"cloud_provider"`
-		DeploymentType string `json:"deployment_type"`
-		EntryFunction  string `json:"entry_function"`
+		Runtime        string       `json:"runtime"`
+		PythonManager  string       `json:"python_manager,omitempty"`
+		PythonBuild    *PythonBuild `json:"python_build,omitempty"`
+		NodeBuild      *NodeBuild   `json:"node_build,omitempty"`
+		CloudProvider  string       `json:"cloud_provider"`
+		DeploymentType string       `json:"deployment_type"`
+		EntryFunction  string       `json:"entry_function"`
+		Architecture   string       `json:"architecture,omitempty"`
+		Environment    *Environment `json:"environment,omitempty"`
+		Resources      *Resources   `json:"resources,omitempty"`
 		AWS            struct {
-			RestApiResourceID string `json:"rest_api_resource_id,omitempty"`
+			AWSDeployment
+			Environments map[string]*AWSDeployment `json:"environments,omitempty"`
 		} `json:"deploy_settings,omitempty"`
+		GoogleCloud struct {
+			CloudRun map[string]*CloudRunDeployment `json:"cloud_run,omitempty"`
+		} `json:"gcloud_deploy_settings,omitempty"`
 	} `json:"config"`
 	Template []struct {
 		Prompt string `json:"prompt"`
@@ -27,3 +36,34 @@ type Config struct {
 		Style  string `json:"format,omitempty"`
 	} `json:"template,omitempty"`
 }
+
+// PythonBuild are the settings for building a Python function's
+// dependencies from its requirements (with the pip python_manager)
+type PythonBuild struct {
+	// Docker builds the dependencies in a Lambda build image, so that
+	// packages without a Linux wheel can be compiled from source
+	Docker bool `json:"docker,omitempty"`
+}
+
+// NodeBuild are the settings for building a Node.js function
+// that needs to be compiled (e.g. from TypeScript)
+type NodeBuild struct {
+	// BuildScript is the script in package.json that builds the function (e.g. build)
+	BuildScript string `json:"build_script,omitempty"`
+
+	// OutputDirectory is where the build script writes the JavaScript (e.g. dist)
+	OutputDirectory string `json:"output_directory,omitempty"`
+}
+
+// CloudRunDeployment is the last container that was deployed to an environment
+type CloudRunDeployment struct {
+	SourceHash string `json:"source_hash"`
+	Image      string `json:"image"`
+}
+
+// AWSDeployment is the state of a Lambda function in an environment
+type AWSDeployment struct {
+	RestApiResourceID string `json:"rest_api_resource_id,omitempty"`
+	CodeSha256        string `json:"code_sha256,omitempty"`
+	ImageURI          string `json:"image_uri,omitempty"`
+}
@@ -0,0 +1,84 @@
+package emulator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+const (
+	containerPort = 8080
+)
+
+// containerServer builds & runs a Cloud Run container with docker
+type containerServer struct {
+	image          string
+	container      string
+	buildDirectory string
+	variables      map[string]string
+	port           int
+	process        *process
+}
+
+func newContainerServer(cfg *config.Config, buildDirectory string, variables map[string]string, opts *Options) *containerServer {
+	name := fmt.Sprintf("kettle-run-%s", strings.ToLower(cfg.ProjectName))
+	return &containerServer{
+		image:          name,
+		container:      name,
+		buildDirectory: buildDirectory,
+		variables:      variables,
+		port:           opts.Port,
+	}
+}
+
+func (s *containerServer) start() error {
+	err := cli.Execute("docker", []string{
+		"build",
+		"--tag", s.image,
+		".",
+	}, "Building docker container")
+	if err != nil {
+		return err
+	}
+
+	// Values are passed in a file, so that they are not visible in the arguments
+	envFile := path.Join(s.buildDirectory, "env")
+	lines := []string{fmt.Sprintf("PORT=%d", containerPort)}
+	for key, value := range s.variables {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, value))
+	}
+	if err := ioutil.WriteFile(envFile, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return err
+	}
+
+	environ := os.Environ()
+	s.process, err = startProcess("", environ, "docker",
+		"run",
+		"--rm",
+		"--name", s.container,
+		"--publish", fmt.Sprintf("127.0.0.1:%d:%d", s.port, containerPort),
+		"--env-file", envFile,
+		s.image,
+	)
+	return err
+}
+
+// stop stops the container; killing the docker cli would leave it running
+func (s *containerServer) stop() {
+	if s.process == nil {
+		return
+	}
+	if err := cli.Execute("docker", []string{"stop", s.container}, "Stopping docker container"); err != nil {
+		if settings.DebugMode {
+			fmt.Println(err.Error())
+		}
+	}
+	s.process.stop()
+	s.process = nil
+}
@@ -0,0 +1,160 @@
+package emulator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+const (
+	watchInterval = 500 * time.Millisecond
+)
+
+// Options are the options of kettle run
+type Options struct {
+	// Port is the port that the project is served on (localhost:<port>)
+	Port int
+
+	// Reload restarts the project when its files change
+	Reload bool
+}
+
+// server serves a project locally; it is stopped and started
+// again when the project's files change
+type server interface {
+	// start builds the project (if needed) and starts serving it
+	start() error
+
+	// stop stops serving the project; it is safe to call if start failed
+	stop()
+}
+
+// Run serves the project in the current directory on localhost until
+// kettle is interrupted. Long-running processes are started with os/exec,
+// rather than a cli.Runner, so that their output is streamed
+func Run(directory string, cfg *config.Config, env string, opts *Options) error {
+	variables, err := getVariables(directory, cfg, env)
+	if err != nil {
+		return err
+	}
+
+	// Build outputs (e.g. binaries) are written outside of the project
+	buildDirectory, err := ioutil.TempDir("", "kettle-run")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(buildDirectory)
+
+	s, err := newServer(directory, buildDirectory, cfg, variables, opts)
+	if err != nil {
+		return err
+	}
+	if err := s.start(); err != nil {
+		return err
+	}
+	defer s.stop()
+	fmt.Printf("🏃  Running: %s on http://localhost:%d (%s)\n", cfg.ProjectName, opts.Port, cfg.Config.DeploymentType)
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	var changes <-chan struct{}
+	if opts.Reload {
+		changes, err = watch(directory, watchInterval, getBuildOutput(cfg))
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-interrupted:
+			fmt.Println("\n👋  Stopped")
+			return nil
+		case <-changes:
+			// Failed builds are reported, and the project is
+			// started again on the next change
+			fmt.Println("🔄  Files changed, restarting")
+			s.stop()
+			if err := s.start(); err != nil {
+				fmt.Printf("❌ %s\n", err)
+			}
+		}
+	}
+}
+
+func newServer(directory, buildDirectory string, cfg *config.Config, variables map[string]string, opts *Options) (server, error) {
+	switch cfg.Config.DeploymentType {
+	case "lambda":
+		return newLambdaServer(directory, buildDirectory, cfg, variables, opts)
+	case "function":
+		return newFunctionServer(directory, buildDirectory, cfg, variables, opts)
+	case "run":
+		return newContainerServer(cfg, buildDirectory, variables, opts), nil
+	case "lambda-container":
+		// The image's CMD is the handler, so the function can only be run in its container
+		return nil, cli.NewUserInputError("kettle run does not support the lambda-container deployment type; " +
+			"build the Dockerfile and run it with docker run, which serves the Lambda Runtime Interface Emulator of AWS base images")
+	}
+	return nil, cli.NewConfigError(fmt.Errorf("unimplemented service: %s", cfg.Config.DeploymentType))
+}
+
+// getVariables returns the project's environment variables; secrets are
+// not read from the cloud, and can be set in the local environment instead
+func getVariables(directory string, cfg *config.Config, env string) (map[string]string, error) {
+	resolved, err := cfg.ResolveEnvironment(directory, env)
+	if err != nil {
+		return nil, cli.NewConfigError(err)
+	}
+
+	missing := []string{}
+	for key := range resolved.Secrets {
+		if _, ok := os.LookupEnv(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		cli.Warn("Secrets are not read when running locally; set them in your environment: %s", strings.Join(missing, ", "))
+	}
+	return resolved.Variables, nil
+}
+
+// getBuildOutput returns the directory that a project's build writes to (if any),
+// which is not watched, since it changes every time the project is restarted
+func getBuildOutput(cfg *config.Config) string {
+	if cfg.Config.NodeBuild == nil {
+		return ""
+	}
+	return strings.Trim(cfg.Config.NodeBuild.OutputDirectory, "/")
+}
+
+// getEnviron returns the environment of a process that serves the project
+func getEnviron(variables map[string]string, extra ...string) []string {
+	environ := os.Environ()
+	for key, value := range variables {
+		environ = append(environ, fmt.Sprintf("%s=%s", key, value))
+	}
+	return append(environ, extra...)
+}
+
+// getPythonTool returns the path to a tool in the project's virtual
+// environment (venv/ or .venv/), if it has one
+func getPythonTool(directory, name string) string {
+	for _, venv := range []string{"venv", ".venv"} {
+		toolPath := path.Join(directory, venv, "bin", name)
+		if _, err := os.Stat(toolPath); err == nil {
+			return toolPath
+		}
+	}
+	return name
+}
@@ -0,0 +1,27 @@
+package emulator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+func TestNewServerDeploymentTypes(t *testing.T) {
+	cfg := &config.Config{ProjectName: "hello"}
+	cfg.Config.DeploymentType = "lambda-container"
+
+	_, err := newServer(t.TempDir(), t.TempDir(), cfg, nil, &Options{})
+	var userInputError *cli.UserInputError
+	if !errors.As(err, &userInputError) || !strings.Contains(err.Error(), "lambda-container") {
+		t.Errorf("newServer(lambda-container) error = %v, want a user input error that names the deployment type", err)
+	}
+
+	cfg.Config.DeploymentType = "batch"
+	var configError *cli.ConfigError
+	if _, err := newServer(t.TempDir(), t.TempDir(), cfg, nil, &Options{}); !errors.As(err, &configError) {
+		t.Errorf("newServer(batch) error = %v, want a config error", err)
+	}
+}
@@ -0,0 +1,178 @@
+package emulator
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds/aws"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+// goFunctionMain serves a Go Cloud Function, which is an
+// http.HandlerFunc that is exported by the project's module
+var goFunctionMain = template.Must(template.New("main").Parse(`package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	function "{{ .Module }}"
+)
+
+func main() {
+	http.HandleFunc("/", function.{{ .EntryFunction }})
+	log.Fatal(http.ListenAndServe("127.0.0.1:"+os.Getenv("PORT"), nil))
+}
+`))
+
+// functionServer serves a Google Cloud Function with the Functions Framework
+// (Python), or with a generated main package (Go)
+type functionServer struct {
+	directory      string
+	buildDirectory string
+	cfg            *config.Config
+	variables      map[string]string
+	port           int
+	process        *process
+}
+
+func newFunctionServer(directory, buildDirectory string, cfg *config.Config, variables map[string]string, opts *Options) (*functionServer, error) {
+	supported := strings.HasPrefix(cfg.Config.Runtime, "python") ||
+		strings.HasPrefix(cfg.Config.Runtime, "go") ||
+		strings.HasPrefix(cfg.Config.Runtime, "nodejs")
+	if !supported {
+		return nil, cli.NewConfigError(fmt.Errorf("unknown runtime: %s", cfg.Config.Runtime))
+	}
+	return &functionServer{
+		directory:      directory,
+		buildDirectory: buildDirectory,
+		cfg:            cfg,
+		variables:      variables,
+		port:           opts.Port,
+	}, nil
+}
+
+func (s *functionServer) start() error {
+	environ := getEnviron(s.variables, fmt.Sprintf("PORT=%d", s.port))
+
+	if strings.HasPrefix(s.cfg.Config.Runtime, "go") {
+		binaryPath, err := s.buildGoFunction()
+		if err != nil {
+			return err
+		}
+		s.process, err = startProcess(s.directory, environ, binaryPath)
+		return err
+	}
+
+	if strings.HasPrefix(s.cfg.Config.Runtime, "nodejs") {
+		return s.startNodeFunction(environ)
+	}
+
+	// https://github.com/GoogleCloudPlatform/functions-framework-python
+	functionsFramework := getPythonTool(s.directory, "functions-framework")
+	if _, err := exec.LookPath(functionsFramework); err != nil {
+		return &cli.MissingToolError{
+			Tool: "the functions framework (pip install functions-framework)",
+			Err:  err,
+		}
+	}
+	var err error
+	s.process, err = startProcess(s.directory, environ, functionsFramework,
+		"--source", "main.py",
+		"--target", s.cfg.Config.EntryFunction,
+		"--host", "127.0.0.1",
+		"--port", fmt.Sprint(s.port),
+	)
+	return err
+}
+
+// startNodeFunction runs the project's build script (if it has one) with the package
+// manager of its lock file, and serves
+// the function with the functions framework in the project's node_modules
+// https://github.com/GoogleCloudPlatform/functions-framework-nodejs
+func (s *functionServer) startNodeFunction(environ []string) error {
+	if s.cfg.Config.NodeBuild != nil && s.cfg.Config.NodeBuild.BuildScript != "" {
+		if err := aws.RunNodeScript(s.directory, s.cfg.Config.NodeBuild.BuildScript); err != nil {
+			return err
+		}
+	}
+
+	functionsFramework := path.Join(s.directory, "node_modules", ".bin", "functions-framework")
+	if _, err := os.Stat(functionsFramework); err != nil {
+		return &cli.MissingToolError{
+			Tool: "the functions framework (npm install --save-dev @google-cloud/functions-framework)",
+			Err:  err,
+		}
+	}
+	var err error
+	s.process, err = startProcess(s.directory, environ, functionsFramework,
+		"--target", s.cfg.Config.EntryFunction,
+		"--port", fmt.Sprint(s.port),
+	)
+	return err
+}
+
+func (s *functionServer) stop() {
+	s.process.stop()
+	s.process = nil
+}
+
+// buildGoFunction builds a main package, outside of the project, that imports
+// the project's module; it is built from the project directory so that it
+// uses the project's go.mod
+func (s *functionServer) buildGoFunction() (string, error) {
+	module, err := getGoModule(s.directory)
+	if err != nil {
+		return "", err
+	}
+
+	mainPath := path.Join(s.buildDirectory, "main.go")
+	f, err := os.Create(mainPath)
+	if err != nil {
+		return "", err
+	}
+	err = goFunctionMain.Execute(f, map[string]string{
+		"Module":        module,
+		"EntryFunction": s.cfg.Config.EntryFunction,
+	})
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	binaryPath := path.Join(s.buildDirectory, "function")
+	err = cli.Execute("go", []string{
+		"build",
+		"-o", binaryPath,
+		mainPath,
+	}, "Building Go binary")
+	if err != nil {
+		return "", err
+	}
+	return binaryPath, nil
+}
+
+// getGoModule returns the module path in a directory's go.mod
+func getGoModule(directory string) (string, error) {
+	data, err := ioutil.ReadFile(path.Join(directory, "go.mod"))
+	if err != nil {
+		return "", cli.NewConfigError(fmt.Errorf("a go.mod is needed to run a Go Cloud Function: %w", err))
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	return "", cli.NewConfigError(fmt.Errorf("go.mod does not have a module path"))
+}
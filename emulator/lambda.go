@@ -0,0 +1,176 @@
+package emulator
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/operatorai/kettle-cli/cli"
+	"github.com/operatorai/kettle-cli/clouds/aws"
+	"github.com/operatorai/kettle-cli/config"
+)
+
+const (
+	// Lambda's default timeout is 3 seconds; this is
+	// longer, so that functions can be debugged
+	defaultLambdaTimeout = 60 * time.Second
+	defaultLambdaMemory  = 128
+)
+
+//go:embed lambda_runtime.py
+var pythonRuntimeClient []byte
+
+//go:embed lambda_runtime.js
+var nodeRuntimeClient []byte
+
+// lambdaServer serves a Lambda function in the same way as kettle's REST API
+// integration: the request body is the event, and the function's return
+// value is the response body (with a 500 status if the function fails)
+type lambdaServer struct {
+	directory      string
+	buildDirectory string
+	cfg            *config.Config
+	handler        string
+	variables      map[string]string
+	port           int
+	timeout        time.Duration
+	memory         int
+
+	api        *runtimeAPI
+	apiAddress string
+	process    *process
+}
+
+func newLambdaServer(directory, buildDirectory string, cfg *config.Config, variables map[string]string, opts *Options) (*lambdaServer, error) {
+	handler, err := aws.GetLambdaHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := cfg.ResolveResources("")
+	if err != nil {
+		return nil, cli.NewConfigError(err)
+	}
+
+	s := &lambdaServer{
+		directory:      directory,
+		buildDirectory: buildDirectory,
+		cfg:            cfg,
+		handler:        handler,
+		variables:      variables,
+		port:           opts.Port,
+		timeout:        defaultLambdaTimeout,
+		memory:         defaultLambdaMemory,
+		api:            newRuntimeAPI(cfg.ProjectName),
+	}
+	if resources.TimeoutSeconds != 0 {
+		s.timeout = time.Duration(resources.TimeoutSeconds) * time.Second
+	}
+	if resources.MemoryMB != 0 {
+		s.memory = resources.MemoryMB
+	}
+
+	// The runtime API listens on a random port; requests are served on opts.Port
+	apiListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		apiListener.Close()
+		return nil, &cli.UserInputError{Err: err}
+	}
+	s.apiAddress = apiListener.Addr().String()
+	go http.Serve(apiListener, s.api)
+	go http.Serve(listener, s)
+	return s, nil
+}
+
+func (s *lambdaServer) start() error {
+	environ := getEnviron(s.variables,
+		fmt.Sprintf("AWS_LAMBDA_RUNTIME_API=%s", s.apiAddress),
+		fmt.Sprintf("AWS_LAMBDA_FUNCTION_NAME=%s", s.cfg.ProjectName),
+		fmt.Sprintf("AWS_LAMBDA_FUNCTION_MEMORY_SIZE=%d", s.memory),
+		fmt.Sprintf("_HANDLER=%s", s.handler),
+	)
+
+	var err error
+	switch {
+	case aws.IsGoRuntime(s.cfg.Config.Runtime):
+		binaryPath := path.Join(s.buildDirectory, "bootstrap")
+		err = cli.Execute("go", []string{
+			"build",
+			"-tags", "lambda.norpc",
+			"-o", binaryPath,
+			".",
+		}, "Building Go binary")
+		if err != nil {
+			return err
+		}
+		s.process, err = startProcess(s.directory, environ, binaryPath)
+		return err
+
+	case strings.HasPrefix(s.cfg.Config.Runtime, "nodejs"):
+		if s.cfg.Config.NodeBuild != nil && s.cfg.Config.NodeBuild.BuildScript != "" {
+			if err := aws.RunNodeScript(s.directory, s.cfg.Config.NodeBuild.BuildScript); err != nil {
+				return err
+			}
+		}
+		runtimeClient := path.Join(s.buildDirectory, "lambda_runtime.js")
+		if err := ioutil.WriteFile(runtimeClient, nodeRuntimeClient, 0644); err != nil {
+			return err
+		}
+		s.process, err = startProcess(s.directory, environ, "node", runtimeClient)
+		return err
+	}
+
+	runtimeClient := path.Join(s.buildDirectory, "lambda_runtime.py")
+	if err := ioutil.WriteFile(runtimeClient, pythonRuntimeClient, 0644); err != nil {
+		return err
+	}
+	s.process, err = startProcess(s.directory, environ, getPythonTool(s.directory, "python3"), runtimeClient)
+	return err
+}
+
+func (s *lambdaServer) stop() {
+	s.process.stop()
+	s.process = nil
+}
+
+func (s *lambdaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Bodies that are not JSON are passed to the function as a string
+	event := body
+	if len(strings.TrimSpace(string(body))) == 0 {
+		event = []byte("{}")
+	} else if !json.Valid(body) {
+		event, _ = json.Marshal(string(body))
+	}
+
+	start := time.Now()
+	result, err := s.api.invoke(event, s.timeout)
+	status := http.StatusOK
+	switch {
+	case err != nil:
+		status = http.StatusGatewayTimeout
+		body, _ := json.Marshal(map[string]string{"errorMessage": err.Error()})
+		result = &invocationResult{body: body}
+	case result.failed:
+		status = http.StatusInternalServerError
+	}
+	fmt.Printf("➡️  %s %s %d (%s)\n", r.Method, r.URL.Path, status, time.Since(start).Round(time.Millisecond))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(result.body)
+}
@@ -0,0 +1,112 @@
+// A minimal Lambda runtime client for running Node.js functions with kettle run.
+//
+// It fetches events from the Lambda Runtime API (AWS_LAMBDA_RUNTIME_API),
+// calls the handler (_HANDLER, e.g. index.handler) and posts its response.
+"use strict";
+
+const fs = require("fs");
+const http = require("http");
+const path = require("path");
+const { pathToFileURL } = require("url");
+
+const basePath = "/2018-06-01/runtime";
+
+function request(method, requestPath, payload) {
+  const [host, port] = process.env.AWS_LAMBDA_RUNTIME_API.split(":");
+  return new Promise((resolve, reject) => {
+    const req = http.request(
+      { host, port, method, path: basePath + requestPath, headers: { "Content-Type": "application/json" } },
+      (response) => {
+        const chunks = [];
+        response.on("data", (chunk) => chunks.push(chunk));
+        response.on("end", () => resolve({ headers: response.headers, body: Buffer.concat(chunks).toString() }));
+      }
+    );
+    req.on("error", reject);
+    if (payload !== undefined) {
+      req.write(JSON.stringify(payload));
+    }
+    req.end();
+  });
+}
+
+function errorPayload(error) {
+  const stack = error && error.stack ? String(error.stack).split("\n") : [];
+  return {
+    errorMessage: error && error.message ? error.message : String(error),
+    errorType: error && error.name ? error.name : "Error",
+    stackTrace: stack,
+  };
+}
+
+// loadHandler imports the module of a handler (e.g. dist/index.handler), which
+// can be CommonJS or an ES module
+async function loadHandler(handler) {
+  const separator = handler.lastIndexOf(".");
+  const modulePath = path.resolve(process.cwd(), handler.slice(0, separator));
+  const functionName = handler.slice(separator + 1);
+
+  const extension = [".js", ".mjs", ".cjs"].find((ext) => fs.existsSync(modulePath + ext));
+  if (extension === undefined) {
+    throw new Error(`Cannot find module ${modulePath}`);
+  }
+  const module = await import(pathToFileURL(modulePath + extension).href);
+  const fn = module[functionName] || (module.default && module.default[functionName]);
+  if (typeof fn !== "function") {
+    throw new Error(`${handler} is not a function`);
+  }
+  return fn;
+}
+
+// invoke calls a handler that returns a promise, or one that takes a callback
+function invoke(fn, event, context) {
+  return new Promise((resolve, reject) => {
+    const callback = (error, result) => (error ? reject(error) : resolve(result));
+    try {
+      const result = fn(event, context, callback);
+      if (result && typeof result.then === "function") {
+        result.then(resolve, reject);
+      } else if (fn.length < 3) {
+        resolve(result);
+      }
+    } catch (error) {
+      reject(error);
+    }
+  });
+}
+
+async function main() {
+  let fn;
+  try {
+    fn = await loadHandler(process.env._HANDLER);
+  } catch (error) {
+    console.error(error);
+    await request("POST", "/init/error", errorPayload(error));
+    process.exit(1);
+  }
+
+  for (;;) {
+    const next = await request("GET", "/invocation/next");
+    const requestId = next.headers["lambda-runtime-aws-request-id"];
+    const deadlineMs = Number(next.headers["lambda-runtime-deadline-ms"]);
+    const context = {
+      awsRequestId: requestId,
+      invokedFunctionArn: next.headers["lambda-runtime-invoked-function-arn"],
+      functionName: process.env.AWS_LAMBDA_FUNCTION_NAME || "",
+      functionVersion: "$LATEST",
+      memoryLimitInMB: process.env.AWS_LAMBDA_FUNCTION_MEMORY_SIZE || "128",
+      callbackWaitsForEmptyEventLoop: true,
+      getRemainingTimeInMillis: () => Math.max(deadlineMs - Date.now(), 0),
+    };
+
+    try {
+      const result = await invoke(fn, JSON.parse(next.body || "null"), context);
+      await request("POST", `/invocation/${requestId}/response`, result === undefined ? null : result);
+    } catch (error) {
+      console.error(error);
+      await request("POST", `/invocation/${requestId}/error`, errorPayload(error));
+    }
+  }
+}
+
+main();
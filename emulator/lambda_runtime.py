@@ -0,0 +1,73 @@
+"""A minimal Lambda runtime client for running Python functions with kettle run.
+
+It fetches events from the Lambda Runtime API (AWS_LAMBDA_RUNTIME_API),
+calls the handler (_HANDLER, e.g. main.handler) and posts its response.
+"""
+import importlib
+import json
+import os
+import sys
+import time
+import traceback
+import urllib.request
+
+
+class Context:
+    def __init__(self, request_id, deadline_ms, function_arn):
+        self.aws_request_id = request_id
+        self.invoked_function_arn = function_arn
+        self.function_name = os.environ.get("AWS_LAMBDA_FUNCTION_NAME", "")
+        self.function_version = "$LATEST"
+        self.memory_limit_in_mb = os.environ.get("AWS_LAMBDA_FUNCTION_MEMORY_SIZE", "128")
+        self._deadline_ms = deadline_ms
+
+    def get_remaining_time_in_millis(self):
+        return max(self._deadline_ms - int(time.time() * 1000), 0)
+
+
+def post(url, payload):
+    data = json.dumps(payload).encode("utf-8")
+    request = urllib.request.Request(url, data=data, method="POST")
+    request.add_header("Content-Type", "application/json")
+    urllib.request.urlopen(request).close()
+
+
+def error_payload(error):
+    return {
+        "errorMessage": str(error),
+        "errorType": type(error).__name__,
+        "stackTrace": traceback.format_tb(error.__traceback__),
+    }
+
+
+def main():
+    base_url = "http://{}/2018-06-01/runtime".format(os.environ["AWS_LAMBDA_RUNTIME_API"])
+    module_name, function_name = os.environ["_HANDLER"].rsplit(".", 1)
+    sys.path.insert(0, os.getcwd())
+
+    try:
+        handler = getattr(importlib.import_module(module_name), function_name)
+    except Exception as error:
+        traceback.print_exc()
+        post(base_url + "/init/error", error_payload(error))
+        sys.exit(1)
+
+    while True:
+        with urllib.request.urlopen(base_url + "/invocation/next") as response:
+            request_id = response.headers["Lambda-Runtime-Aws-Request-Id"]
+            deadline_ms = int(response.headers["Lambda-Runtime-Deadline-Ms"])
+            function_arn = response.headers["Lambda-Runtime-Invoked-Function-Arn"]
+            event = json.loads(response.read() or b"null")
+
+        context = Context(request_id, deadline_ms, function_arn)
+        try:
+            result = handler(event, context)
+        except Exception as error:
+            traceback.print_exc()
+            post("{}/invocation/{}/error".format(base_url, request_id), error_payload(error))
+            continue
+        post("{}/invocation/{}/response".format(base_url, request_id), result)
+
+
+if __name__ == "__main__":
+    main()
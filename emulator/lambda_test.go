@@ -0,0 +1,92 @@
+package emulator
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/operatorai/kettle-cli/config"
+)
+
+func TestNewLambdaServerRuntimes(t *testing.T) {
+	tests := []struct {
+		runtime string
+		want    string
+		wantErr bool
+	}{
+		{"python3.12", "main.handler", false},
+		{"nodejs20.x", "index.handler", false},
+		{"go1.x", "bootstrap", false},
+		{"provided.al2023", "bootstrap", false},
+		{"ruby3.2", "", true},
+	}
+	for _, test := range tests {
+		cfg := &config.Config{ProjectName: "hello"}
+		cfg.Config.Runtime = test.runtime
+		cfg.Config.EntryFunction = "handler"
+
+		s, err := newLambdaServer(t.TempDir(), t.TempDir(), cfg, nil, &Options{})
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("newLambdaServer(%s) did not return an error", test.runtime)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("newLambdaServer(%s): %v", test.runtime, err)
+		}
+		if s.handler != test.want {
+			t.Errorf("newLambdaServer(%s) handler = %s, want %s", test.runtime, s.handler, test.want)
+		}
+	}
+}
+
+func TestNodeLambda(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+
+	directory := t.TempDir()
+	handler := `exports.handler = async (event, context) => {
+  if (event.fail) {
+    throw new Error("failed");
+  }
+  return { greeting: "hello " + event.name, function: context.functionName };
+};
+`
+	if err := ioutil.WriteFile(path.Join(directory, "index.js"), []byte(handler), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{ProjectName: "hello"}
+	cfg.Config.Runtime = "nodejs20.x"
+	cfg.Config.EntryFunction = "handler"
+	s, err := newLambdaServer(directory, t.TempDir(), cfg, nil, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.stop()
+
+	tests := []struct {
+		event      string
+		wantStatus int
+		wantBody   string
+	}{
+		{`{"name": "kettle"}`, http.StatusOK, `{"greeting":"hello kettle","function":"hello"}`},
+		{`{"fail": true}`, http.StatusInternalServerError, `"errorMessage":"failed"`},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.event)))
+		if recorder.Code != test.wantStatus || !strings.Contains(recorder.Body.String(), test.wantBody) {
+			t.Errorf("POST %s = %d %s, want %d %s", test.event, recorder.Code, recorder.Body.String(), test.wantStatus, test.wantBody)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package emulator
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/operatorai/kettle-cli/cli"
+)
+
+// process is a long-running command that serves a project
+type process struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+func startProcess(directory string, environ []string, name string, args ...string) (*process, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = directory
+	cmd.Env = environ
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, &cli.MissingToolError{
+				Tool: name,
+				Err:  err,
+			}
+		}
+		return nil, err
+	}
+
+	p := &process{
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+	go func() {
+		_ = cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// stop kills the process, and waits for it to exit
+func (p *process) stop() {
+	if p == nil {
+		return
+	}
+	_ = p.cmd.Process.Kill()
+	<-p.done
+}
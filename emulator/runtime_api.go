@@ -0,0 +1,148 @@
+package emulator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	runtimeAPIPrefix = "/2018-06-01/runtime/"
+)
+
+// invocation is a request that is waiting for the function to respond
+type invocation struct {
+	id       string
+	event    []byte
+	deadline time.Time
+	result   chan *invocationResult
+}
+
+type invocationResult struct {
+	body   []byte
+	failed bool
+}
+
+// runtimeAPI implements the parts of the Lambda Runtime API that are used by
+// runtime clients (e.g. aws-lambda-go) to fetch events & post responses
+// https://docs.aws.amazon.com/lambda/latest/dg/runtimes-api.html
+type runtimeAPI struct {
+	functionArn string
+	invocations chan *invocation
+
+	mutex   sync.Mutex
+	pending map[string]*invocation
+}
+
+func newRuntimeAPI(functionName string) *runtimeAPI {
+	return &runtimeAPI{
+		functionArn: fmt.Sprintf("arn:aws:lambda:local:000000000000:function:%s", functionName),
+		invocations: make(chan *invocation),
+		pending:     map[string]*invocation{},
+	}
+}
+
+// invoke sends an event to the function, and waits for its response
+func (a *runtimeAPI) invoke(event []byte, timeout time.Duration) (*invocationResult, error) {
+	id, err := newRequestID()
+	if err != nil {
+		return nil, err
+	}
+	inv := &invocation{
+		id:       id,
+		event:    event,
+		deadline: time.Now().Add(timeout),
+		result:   make(chan *invocationResult, 1),
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case a.invocations <- inv:
+	case <-timer.C:
+		return nil, fmt.Errorf("the function did not start within %s", timeout)
+	}
+
+	select {
+	case result := <-inv.result:
+		return result, nil
+	case <-timer.C:
+		a.mutex.Lock()
+		delete(a.pending, inv.id)
+		a.mutex.Unlock()
+		return nil, fmt.Errorf("task timed out after %s", timeout)
+	}
+}
+
+func (a *runtimeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := strings.Split(strings.TrimPrefix(r.URL.Path, runtimeAPIPrefix), "/")
+	switch {
+	case len(route) == 2 && route[0] == "invocation" && route[1] == "next" && r.Method == http.MethodGet:
+		a.next(w, r)
+	case len(route) == 3 && route[0] == "invocation" && r.Method == http.MethodPost:
+		a.respond(w, r, route[1], route[2] == "error")
+	case len(route) == 2 && route[0] == "init" && route[1] == "error" && r.Method == http.MethodPost:
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Printf("❌ The function failed to start: %s\n", string(body))
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// next blocks until there is an event for the function
+func (a *runtimeAPI) next(w http.ResponseWriter, r *http.Request) {
+	var inv *invocation
+	select {
+	case inv = <-a.invocations:
+	case <-r.Context().Done():
+		return
+	}
+
+	a.mutex.Lock()
+	a.pending[inv.id] = inv
+	a.mutex.Unlock()
+
+	w.Header().Set("Lambda-Runtime-Aws-Request-Id", inv.id)
+	w.Header().Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(inv.deadline.UnixNano()/int64(time.Millisecond), 10))
+	w.Header().Set("Lambda-Runtime-Invoked-Function-Arn", a.functionArn)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(inv.event)
+}
+
+// respond passes the function's response (or error) back to the request
+func (a *runtimeAPI) respond(w http.ResponseWriter, r *http.Request, id string, failed bool) {
+	a.mutex.Lock()
+	inv, ok := a.pending[id]
+	delete(a.pending, id)
+	a.mutex.Unlock()
+	if !ok {
+		http.Error(w, "unknown request ID", http.StatusBadRequest)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	inv.result <- &invocationResult{
+		body:   body,
+		failed: failed,
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
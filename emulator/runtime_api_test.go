@@ -0,0 +1,118 @@
+package emulator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runFunction acts as a runtime client: it fetches the next event,
+// and posts a response (or an error) for it
+func runFunction(t *testing.T, url string, respond func(event []byte) ([]byte, bool)) {
+	t.Helper()
+	response, err := http.Get(url + runtimeAPIPrefix + "invocation/next")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	event, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	id := response.Header.Get("Lambda-Runtime-Aws-Request-Id")
+	if id == "" || response.Header.Get("Lambda-Runtime-Deadline-Ms") == "" {
+		t.Errorf("missing runtime headers: %v", response.Header)
+	}
+	if arn := response.Header.Get("Lambda-Runtime-Invoked-Function-Arn"); !strings.HasSuffix(arn, ":function:hello") {
+		t.Errorf("function ARN = %s", arn)
+	}
+
+	body, failed := respond(event)
+	suffix := "response"
+	if failed {
+		suffix = "error"
+	}
+	result, err := http.Post(url+runtimeAPIPrefix+"invocation/"+id+"/"+suffix, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	result.Body.Close()
+	if result.StatusCode != http.StatusAccepted {
+		t.Errorf("POST %s = %d, want %d", suffix, result.StatusCode, http.StatusAccepted)
+	}
+}
+
+func TestRuntimeAPIInvoke(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      string
+		response   string
+		failed     bool
+		wantFailed bool
+	}{
+		{"response", `{"name": "kettle"}`, `{"greeting": "hello kettle"}`, false, false},
+		{"error", `{}`, `{"errorMessage": "failed"}`, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := newRuntimeAPI("hello")
+			server := httptest.NewServer(api)
+			defer server.Close()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				runFunction(t, server.URL, func(event []byte) ([]byte, bool) {
+					if string(event) != test.event {
+						t.Errorf("event = %s, want %s", event, test.event)
+					}
+					return []byte(test.response), test.failed
+				})
+			}()
+
+			result, err := api.invoke([]byte(test.event), 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-done
+			if string(result.body) != test.response || result.failed != test.wantFailed {
+				t.Errorf("result = %s (failed: %t), want %s (failed: %t)", result.body, result.failed, test.response, test.wantFailed)
+			}
+		})
+	}
+}
+
+func TestRuntimeAPITimeout(t *testing.T) {
+	api := newRuntimeAPI("hello")
+
+	// Nothing fetches the event
+	if _, err := api.invoke([]byte(`{}`), 10*time.Millisecond); err == nil || !strings.Contains(err.Error(), "did not start") {
+		t.Errorf("invoke() err = %v, want a start timeout", err)
+	}
+}
+
+func TestRuntimeAPIRoutes(t *testing.T) {
+	api := newRuntimeAPI("hello")
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodPost, "invocation/unknown-id/response", http.StatusBadRequest},
+		{http.MethodPost, "init/error", http.StatusAccepted},
+		{http.MethodGet, "invocation/unknown-id/response", http.StatusNotFound},
+		{http.MethodGet, "unknown", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, runtimeAPIPrefix+test.path, strings.NewReader(`{}`))
+		recorder := httptest.NewRecorder()
+		api.ServeHTTP(recorder, request)
+		if recorder.Code != test.want {
+			t.Errorf("%s %s = %d, want %d", test.method, test.path, recorder.Code, test.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package emulator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/operatorai/kettle-cli/ignore"
+	"github.com/operatorai/kettle-cli/settings"
+)
+
+// watch polls the files in a directory that are not in .kettleignore (or in
+// the excluded directory, if it is set), and sends on the channel when
+// any of them are added, removed or changed
+func watch(directory string, interval time.Duration, excluded string) (<-chan struct{}, error) {
+	matcher, err := ignore.Load(directory)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := snapshot(directory, matcher, excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan struct{})
+	go func() {
+		for range time.Tick(interval) {
+			current, err := snapshot(directory, matcher, excluded)
+			if err != nil {
+				// e.g. a file was removed while the directory was walked
+				if settings.DebugMode {
+					fmt.Println(err.Error())
+				}
+				continue
+			}
+			if !snapshotsEqual(previous, current) {
+				previous = current
+				changes <- struct{}{}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func snapshot(directory string, matcher *ignore.Matcher, excluded string) (map[string]fileState, error) {
+	files, err := ignore.Files(directory, matcher)
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]fileState{}
+	for _, file := range files {
+		if excluded != "" && strings.HasPrefix(file, excluded+"/") {
+			continue
+		}
+		info, err := os.Stat(path.Join(directory, file))
+		if err != nil {
+			return nil, err
+		}
+		states[file] = fileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+	return states, nil
+}
+
+func snapshotsEqual(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, state := range a {
+		other, ok := b[file]
+		if !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
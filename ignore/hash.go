@@ -0,0 +1,41 @@
+package ignore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Hash returns a hash of the files in a directory that are not ignored,
+// which changes if any of their paths or contents change
+func Hash(directory string, m *Matcher) (string, error) {
+	files, err := Files(directory, m)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		// Both the path and the contents of each file are hashed
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+		if err := hashFile(h, filepath.Join(directory, file)); err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,260 @@
+package ignore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ignoreFileName = ".kettleignore"
+)
+
+// Patterns that are always applied before those in .kettleignore; they
+// can be re-included with a negated pattern (e.g. !tests/)
+var defaultPatterns = []string{
+	".git/",
+	".hg/",
+	".svn/",
+	".DS_Store",
+	".kettle/",
+	".kettleignore",
+	"kettle.json",
+	"deployment.zip",
+	"venv/",
+	".venv/",
+	"__pycache__/",
+	"*.pyc",
+	".pytest_cache/",
+	".mypy_cache/",
+	"node_modules/",
+	".idea/",
+	".vscode/",
+	".env",
+	".env.*",
+	"*.pem",
+	"*.key",
+}
+
+type pattern struct {
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher matches paths against gitignore-style patterns
+type Matcher struct {
+	patterns []*pattern
+}
+
+// Load returns a matcher with the default patterns and those in
+// the directory's .kettleignore file (if it has one)
+func Load(directory string) (*Matcher, error) {
+	lines, err := Lines(directory)
+	if err != nil {
+		return nil, err
+	}
+	return New(lines), nil
+}
+
+func New(lines []string) *Matcher {
+	matcher := &Matcher{}
+	matcher.Add(lines)
+	return matcher
+}
+
+// Add parses lines in the gitignore format; later
+// patterns take precedence over earlier ones
+func (m *Matcher) Add(lines []string) {
+	for _, line := range lines {
+		if p := parsePattern(line); p != nil {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+}
+
+// Match returns whether a path (relative to the project directory,
+// using forward slashes) should be ignored
+func (m *Matcher) Match(relativePath string, isDir bool) bool {
+	relativePath = strings.TrimPrefix(path.Clean(relativePath), "./")
+
+	// A path is ignored if any of its parent directories are ignored
+	parts := strings.Split(relativePath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(relativePath, isDir)
+}
+
+func (m *Matcher) match(relativePath string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.regex.MatchString(relativePath) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// Lines returns the default patterns (and any other virtual environments in the
+// directory) followed by those in the directory's .kettleignore file, e.g. to
+// write them to a .gcloudignore file
+func Lines(directory string) ([]string, error) {
+	lines := append([]string{}, defaultPatterns...)
+	virtualenvs, err := virtualenvPatterns(directory)
+	if err != nil {
+		return nil, err
+	}
+	lines = append(lines, virtualenvs...)
+
+	data, err := os.ReadFile(path.Join(directory, ignoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lines, nil
+		}
+		return nil, err
+	}
+	return append(lines, strings.Split(string(data), "\n")...), nil
+}
+
+// virtualenvPatterns returns patterns for the virtual environments in a directory
+// that are not named venv/ or .venv/ (e.g. env/), which have a pyvenv.cfg file;
+// other directories with those names (e.g. a package named env) are deployed
+func virtualenvPatterns(directory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	patterns := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(path.Join(directory, entry.Name(), "pyvenv.cfg")); err == nil {
+			patterns = append(patterns, fmt.Sprintf("/%s/", entry.Name()))
+		}
+	}
+	return patterns, nil
+}
+
+// Files returns the files in a directory that are not ignored,
+// as sorted paths relative to the directory
+func Files(directory string, m *Matcher) ([]string, error) {
+	files := []string{}
+	err := filepath.Walk(directory, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		if relativePath == "." {
+			return nil
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		if m.Match(relativePath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, relativePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// parsePattern converts a gitignore pattern into a regular expression
+// https://git-scm.com/docs/gitignore#_pattern_format
+func parsePattern(line string) *pattern {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	p := &pattern{}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	// Patterns with a slash (other than at the end) are relative to
+	// the project directory; others match at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case strings.HasPrefix(line[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "/**") && i+3 == len(line):
+			expr.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				expr.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := line[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	regex, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil
+	}
+	p.regex = regex
+	return p
+}
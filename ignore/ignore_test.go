@@ -0,0 +1,110 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{"name at any depth", []string{"*.log"}, "logs/app/debug.log", false, true},
+		{"name does not match", []string{"*.log"}, "main.py", false, false},
+		{"directory pattern", []string{"build/"}, "build", true, true},
+		{"directory pattern on a file", []string{"build/"}, "build", false, false},
+		{"file in ignored directory", []string{"build/"}, "build/out/main.js", false, true},
+		{"anchored", []string{"/config.json"}, "config.json", false, true},
+		{"anchored in subdirectory", []string{"/config.json"}, "app/config.json", false, false},
+		{"path with slash", []string{"docs/*.md"}, "docs/readme.md", false, true},
+		{"single star does not cross directories", []string{"docs/*.md"}, "docs/api/readme.md", false, false},
+		{"double star", []string{"docs/**/*.md"}, "docs/api/v1/readme.md", false, true},
+		{"trailing double star", []string{"data/**"}, "data/raw/a.csv", false, true},
+		{"question mark", []string{"file?.txt"}, "file1.txt", false, true},
+		{"character class", []string{"file[0-9].txt"}, "filea.txt", false, false},
+		{"negated class", []string{"file[!0-9].txt"}, "filea.txt", false, true},
+		{"negation", []string{"*.json", "!package.json"}, "package.json", false, false},
+		{"later pattern wins", []string{"!package.json", "*.json"}, "package.json", false, true},
+		{"comments and blanks", []string{"# *.py", "", "  "}, "main.py", false, false},
+		{"escaped", []string{`\#notes`}, "#notes", false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := New(test.patterns).Match(test.path, test.isDir); got != test.want {
+				t.Errorf("Match(%q) with %q = %t, want %t", test.path, test.patterns, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPatterns(t *testing.T) {
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{".git/config", false, true},
+		{"venv", true, true},
+		{"src/__pycache__/main.cpython-39.pyc", false, true},
+		{"node_modules/left-pad/index.js", false, true},
+		{"kettle.json", false, true},
+		{".env.prod", false, true},
+		{"tests/test_main.py", false, false},
+		{"env/settings.py", false, false},
+		{"main.py", false, false},
+		{"requirements.txt", false, false},
+	}
+	matcher := New(defaultPatterns)
+	for _, test := range tests {
+		if got := matcher.Match(test.path, test.isDir); got != test.want {
+			t.Errorf("Match(%q) = %t, want %t", test.path, got, test.want)
+		}
+	}
+}
+
+func TestLoadAndFiles(t *testing.T) {
+	directory := t.TempDir()
+	files := map[string]string{
+		".kettleignore":         "*.csv\n!keep.csv\ntests/\n",
+		"main.py":               "",
+		"data/big.csv":          "",
+		"data/keep.csv":         "",
+		"tests/test_main.py":    "",
+		"__pycache__/main.pyc":  "",
+		"env/__init__.py":       "",
+		"virtualenv/pyvenv.cfg": "",
+		"virtualenv/lib/x.py":   "",
+		"app/venv/pyvenv.cfg":   "",
+		"app/venv/lib/site.py":  "",
+	}
+	for name, contents := range files {
+		filePath := filepath.Join(directory, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matcher, err := Load(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Files(directory, matcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A package named env is deployed, but a virtual environment (with a pyvenv.cfg) is not
+	want := []string{"data/keep.csv", "env/__init__.py", "main.py"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
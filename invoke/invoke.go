@@ -0,0 +1,122 @@
+package invoke
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Options are the options of kettle invoke
+type Options struct {
+	// APIKey is sent in the x-api-key header (AWS API Gateway)
+	APIKey string
+
+	// IdentityToken sends a Google Cloud identity token
+	// for services that do not allow unauthenticated calls
+	IdentityToken bool
+
+	// Direct invokes the function without its HTTP endpoint (AWS only)
+	Direct bool
+}
+
+// Endpoint is the URL of a deployed function or service, and
+// the headers that are needed to call it (e.g. an API key)
+type Endpoint struct {
+	URL     string
+	Headers map[string]string
+}
+
+// Response is the result of invoking a function, either over HTTP
+// or directly (e.g. with aws lambda invoke)
+type Response struct {
+	StatusCode int
+	Status     string
+	Headers    map[string]string
+	Body       []byte
+	Latency    time.Duration
+
+	// FunctionError is set if the function raised an error
+	// when it was invoked directly
+	FunctionError string
+}
+
+// Post sends the payload to the endpoint as JSON
+func Post(endpoint *Endpoint, payload []byte) (*Response, error) {
+	request, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range endpoint.Headers {
+		request.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	for key, values := range response.Header {
+		headers[key] = strings.Join(values, ", ")
+	}
+	return &Response{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Headers:    headers,
+		Body:       body,
+		Latency:    time.Since(start),
+	}, nil
+}
+
+// Write prints the status, headers, latency and body of a response;
+// JSON bodies are indented
+func (r *Response) Write(w io.Writer) {
+	status := r.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+	fmt.Fprintf(w, "Status:  %s\n", status)
+	fmt.Fprintf(w, "Latency: %s\n", r.Latency.Round(time.Millisecond))
+	if r.FunctionError != "" {
+		fmt.Fprintf(w, "Error:   %s\n", r.FunctionError)
+	}
+
+	keys := make([]string, 0, len(r.Headers))
+	for key := range r.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		fmt.Fprintln(w, "Headers:")
+		for _, key := range keys {
+			fmt.Fprintf(w, "  %s: %s\n", key, r.Headers[key])
+		}
+	}
+
+	fmt.Fprintln(w)
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, r.Body, "", "  "); err == nil {
+		fmt.Fprintln(w, indented.String())
+		return
+	}
+	fmt.Fprintln(w, string(r.Body))
+}
+
+// Succeeded returns true for 2xx responses from functions that did not raise an error
+func (r *Response) Succeeded() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300 && r.FunctionError == ""
+}
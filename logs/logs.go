@@ -0,0 +1,45 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Options are the options of kettle logs
+type Options struct {
+	// Follow keeps streaming new log entries until interrupted
+	Follow bool
+
+	// Since is how far back to read, e.g. 30s, 10m, 2h or 1d
+	Since string
+
+	// Filter is a pattern in the syntax of the cloud's logging service
+	Filter string
+
+	// JSON prints the log entries as JSON lines (with --output json)
+	JSON bool
+}
+
+var sincePattern = regexp.MustCompile(`^(\d+)([smhdw])$`)
+
+// SinceDuration parses Since, which also supports days (d) and weeks (w)
+func (o *Options) SinceDuration() (time.Duration, error) {
+	match := sincePattern.FindStringSubmatch(o.Since)
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration: %s (use e.g. 30s, 10m, 2h or 1d)", o.Since)
+	}
+	value, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, err
+	}
+	unit := map[string]time.Duration{
+		"s": time.Second,
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}[match[2]]
+	return time.Duration(value) * unit, nil
+}
@@ -0,0 +1,70 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Environment names are used in function names and API Gateway
+// stages, so they are limited to the characters that both allow
+var environmentNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ParseEnvironmentNames splits a comma-separated list of environment names
+func ParseEnvironmentNames(value string) ([]string, error) {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if !environmentNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment name: %q (use letters, numbers, - and _)", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// EnvironmentNames returns the sorted names of the AWS environments
+func (s *AWSSettings) EnvironmentNames() []string {
+	names := []string{}
+	for name := range s.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnvironmentNames returns the sorted names of the Google Cloud environments
+func (s *GoogleCloudSettings) EnvironmentNames() []string {
+	names := []string{}
+	for name := range s.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// migrateEnvironments moves the dev & prod projects from older
+// settings files into the named environments
+func (s *GoogleCloudSettings) migrateEnvironments() {
+	if s.Environments == nil {
+		s.Environments = map[string]*GoogleCloudProject{}
+	}
+	if s.DevProject != nil {
+		if _, ok := s.Environments["dev"]; !ok {
+			s.Environments["dev"] = s.DevProject
+		}
+		s.DevProject = nil
+	}
+	if s.ProdProject != nil {
+		if _, ok := s.Environments["prod"]; !ok {
+			s.Environments["prod"] = s.ProdProject
+		}
+		s.ProdProject = nil
+	}
+}
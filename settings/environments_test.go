@@ -0,0 +1,78 @@
+package settings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseEnvironmentNames(t *testing.T) {
+	got, err := ParseEnvironmentNames(" dev, staging,,prod,dev ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dev", "staging", "prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEnvironmentNames() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseEnvironmentNames("dev,pro d"); err == nil {
+		t.Error("ParseEnvironmentNames() with a space in a name: want an error")
+	}
+}
+
+func TestMigrateEnvironments(t *testing.T) {
+	older := `
+gcloud:
+  dev_environment:
+    project_name: Hello Dev
+    project_id: hello-dev
+    region: europe-west2
+  prod_environment:
+    project_name: Hello
+    project_id: hello-prod
+    region: europe-west2
+`
+	stg := &Settings{}
+	if err := yaml.Unmarshal([]byte(older), stg); err != nil {
+		t.Fatal(err)
+	}
+	stg.GoogleCloud.migrateEnvironments()
+
+	if got := stg.GoogleCloud.EnvironmentNames(); !reflect.DeepEqual(got, []string{"dev", "prod"}) {
+		t.Fatalf("environments = %v, want [dev prod]", got)
+	}
+	if got := stg.GoogleCloud.Environments["dev"].ProjectID; got != "hello-dev" {
+		t.Errorf("dev project = %q, want hello-dev", got)
+	}
+	if got := stg.GoogleCloud.Environments["prod"].ProjectID; got != "hello-prod" {
+		t.Errorf("prod project = %q, want hello-prod", got)
+	}
+
+	// The older keys are not written back
+	data, err := yaml.Marshal(stg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "dev_environment") || strings.Contains(string(data), "prod_environment") {
+		t.Errorf("migrated settings still have the older keys:\n%s", data)
+	}
+}
+
+func TestMigrateEnvironmentsKeepsNamedEnvironments(t *testing.T) {
+	stg := &GoogleCloudSettings{
+		Environments: map[string]*GoogleCloudProject{
+			"prod": {ProjectID: "hello-live"},
+		},
+		ProdProject: &GoogleCloudProject{ProjectID: "hello-prod"},
+	}
+	stg.migrateEnvironments()
+
+	if got := stg.Environments["prod"].ProjectID; got != "hello-live" {
+		t.Errorf("prod project = %q, want the named environment's hello-live", got)
+	}
+	if stg.ProdProject != nil {
+		t.Error("ProdProject is still set after the migration")
+	}
+}
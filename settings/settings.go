@@ -38,6 +38,9 @@ func ReadSettings() (*Settings, error) {
 	if err := yaml.Unmarshal(contents, &stg); err != nil {
 		return nil, err
 	}
+	if stg.GoogleCloud != nil {
+		stg.GoogleCloud.migrateEnvironments()
+	}
 	if DebugMode {
 		fmt.Printf("\tLoaded settings from: %s\n", settingsFile)
 	}
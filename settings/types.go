@@ -3,6 +3,23 @@ package settings
 // Debug mode (kettle <command> --debug)
 var DebugMode bool
 
+// Non-interactive mode (kettle <command> --non-interactive): prompts
+// fail instead of waiting for input if they do not have an answer
+var NonInteractive bool
+
+// Force mode (kettle deploy --force): deploy even if the
+// code has not changed since the last deployment
+var ForceDeploy bool
+
+// Output format (kettle <command> --output json): with json, commands
+// write a single result document to stdout, and everything else to stderr
+var OutputFormat = OutputFormatText
+
+const (
+	OutputFormatText = "text"
+	OutputFormatJSON = "json"
+)
+
 // Settings are values that do not change across multiple deployments
 // and are therefore stored in a settings file
 
@@ -13,16 +30,33 @@ type GoogleCloudProject struct {
 }
 
 type GoogleCloudSettings struct {
+	// Named environments (e.g. dev, staging, prod), each with its own project & region
+	Environments map[string]*GoogleCloudProject `yaml:"environments,omitempty"`
+
+	// Older settings files have a dev and a prod environment; these
+	// are moved into Environments when the settings are read
 	DevProject  *GoogleCloudProject `yaml:"dev_environment,omitempty"`
 	ProdProject *GoogleCloudProject `yaml:"prod_environment,omitempty"`
 }
 
 type AWSSettings struct {
+	Profile          string `yaml:"profile,omitempty"`
 	AccountID        string `yaml:"account_id,omitempty"`
 	RoleArn          string `yaml:"role_arn,omitempty"`
 	RestApiID        string `yaml:"rest_api_id,omitempty"`
 	RestApiRootID    string `yaml:"rest_api_root_id,omitempty"`
 	DeploymentRegion string `yaml:"region,omitempty"`
+
+	// Deployment archives that are too large to upload directly are uploaded
+	// to this bucket, which keeps the latest ArtifactRetention archives of each
+	// function (the default is 5)
+	ArtifactBucket    string `yaml:"artifact_bucket,omitempty"`
+	ArtifactRetention int    `yaml:"artifact_retention,omitempty"`
+
+	// Named environments (e.g. dev, staging, prod). An environment with
+	// a profile uses its own settings (account, region, role and API); the
+	// others use the settings above. Environments cannot be nested
+	Environments map[string]*AWSSettings `yaml:"environments,omitempty"`
 }
 
 type Settings struct {
@@ -1,8 +1,6 @@
 package templates
 
 import (
-	"errors"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -84,7 +82,7 @@ func searchTemplates(templateName string) (string, error) {
 		return "", err
 	}
 	if !exists {
-		return "", errors.New(fmt.Sprintf("%s not found", templateName))
+		return "", cli.NewUserInputError("%s not found", templateName)
 	}
 	return tempDirectory, nil
 }